@@ -0,0 +1,250 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/util"
+)
+
+// tokenActions are the actions which could be used with access tokens: reading, posting and moderating.
+// The others, e.g. managing the account, its credentials and the site, need a signed in session.
+var tokenActions = map[string]bool{
+	// read
+	"GetAccount":               true,
+	"GetTopics":                true,
+	"GetTopic":                 true,
+	"GetAllCreatedTopics":      true,
+	"GetCreatedTopicsNum":      true,
+	"GetTopicsByNode":          true,
+	"GetTopicsByTab":           true,
+	"GetTopicsNum":             true,
+	"GetHotTopic":              true,
+	"GetTopicWatchLevel":       true,
+	"GetReplies":               true,
+	"GetAllRepliesOfTopic":     true,
+	"GetReply":                 true,
+	"GetLatestReplies":         true,
+	"GetMemberRepliesNum":      true,
+	"GetReplyWithDetails":      true,
+	"GetMemberActivities":      true,
+	"GetFollowingActivities":   true,
+	"GetMembers":               true,
+	"GetMember":                true,
+	"GetMemberAvatar":          true,
+	"GetMemberEditorType":      true,
+	"GetTrustLevel":            true,
+	"GetRankingRich":           true,
+	"GetMemberBadges":          true,
+	"GetBadges":                true,
+	"GetNodes":                 true,
+	"GetNode":                  true,
+	"GetNodePolicy":            true,
+	"GetNodeInfo":              true,
+	"GetNodeRelation":          true,
+	"GetNodesNum":              true,
+	"GetLatestNode":            true,
+	"GetHotNode":               true,
+	"GetNodeNavigation":        true,
+	"GetTabs":                  true,
+	"GetAllTabs":               true,
+	"GetTabWithNodes":          true,
+	"GetPlane":                 true,
+	"GetPlanes":                true,
+	"GetPlaneList":             true,
+	"GetFavorites":             true,
+	"GetFavoritesStatus":       true,
+	"GetAccountFavoriteNum":    true,
+	"GetMemberBlocks":          true,
+	"GetMemberBlockStatus":     true,
+	"GetNotifications":         true,
+	"GetUnreadNotificationNum": true,
+	"GetConsumptionRecord":     true,
+	"GetCheckinBonusStatus":    true,
+	"GetFiles":                 true,
+	"GetFile":                  true,
+	"GetFileNum":               true,
+	"GetCommunityHealth":       true,
+	"GetForumVersion":          true,
+	"GetOnlineNum":             true,
+	// post
+	"AddTopic":              true,
+	"EditContent":           true,
+	"DeleteTopic":           true,
+	"AddTopicHitCount":      true,
+	"AddTopicBrowseCount":   true,
+	"AddNodeBrowseCount":    true,
+	"UpdateTopicWatchLevel": true,
+	"UploadTopicPic":        true,
+	"UploadFile":            true,
+	"AddFileRecord":         true,
+	"DeleteFile":            true,
+	"UpdateFileDescribe":    true,
+	"AddReply":              true,
+	"DeleteReply":           true,
+	"AcceptReply":           true,
+	"UnacceptReply":         true,
+	"AddThanks":             true,
+	"AddFavorites":          true,
+	"DeleteFavorites":       true,
+	"AddMemberBlock":        true,
+	"DeleteMemberBlock":     true,
+	"DeleteNotification":    true,
+	"UpdateReadStatus":      true,
+	// moderate, the moderate scope is checked by CheckPermission
+	"TopTopic":             true,
+	"CancelTopTopic":       true,
+	"UpdateTopicNode":      true,
+	"SuspendMember":        true,
+	"LiftSuspension":       true,
+	"GetMemberSuspensions": true,
+	"GetActiveSuspensions": true,
+	"GetPendingMembers":    true,
+	"HandlePendingMember":  true,
+}
+
+// getRequestAccessToken returns the access token in the Authorization header if it's valid for this request.
+func (c *APIController) getRequestAccessToken() *object.AccessToken {
+	if data := c.Ctx.Input.GetData("accessToken"); data != nil {
+		return data.(*object.AccessToken)
+	}
+
+	header := c.Ctx.Input.Header("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil
+	}
+
+	token := object.GetValidAccessToken(strings.TrimSpace(strings.TrimPrefix(header, "Bearer ")))
+	if token != nil {
+		_, action := c.GetControllerAndAction()
+		if !tokenActions[action] || (token.Scope == object.TokenScopeRead && c.Ctx.Input.Method() != "GET") {
+			token = nil
+		} else if object.IsForbidden(token.MemberId) {
+			token = nil
+		} else {
+			object.UpdateAccessTokenLastUsed(token.Id, util.GetClientIp(c.Ctx.Request))
+		}
+	}
+
+	// the token is looked up once per request
+	if token == nil {
+		c.Ctx.Input.SetData("accessToken", (*object.AccessToken)(nil))
+	} else {
+		c.Ctx.Input.SetData("accessToken", token)
+	}
+	return token
+}
+
+// tokenAllowsModeration returns false if the request uses an access token without the moderate scope.
+func (c *APIController) tokenAllowsModeration() bool {
+	if c.GetSession("username") != nil && c.GetSession("username").(string) != "" {
+		return true
+	}
+
+	token := c.getRequestAccessToken()
+	return token == nil || token.Scope == object.TokenScopeModerate
+}
+
+func (c *APIController) GetAccessTokens() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetMemberAccessTokens(memberId)}
+	c.ServeJSON()
+}
+
+// AddAccessToken creates a token, the plain token is in the response and can't be read again.
+func (c *APIController) AddAccessToken() {
+	if c.RequireLogin() {
+		return
+	}
+
+	var form newAccessTokenForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	var resp Response
+	memberId := c.GetSessionUser()
+	if form.Name == "" || len(form.Name) > 100 {
+		resp = Response{Status: "fail", Msg: "Please name the token with no more than 100 characters."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+	if !object.IsValidTokenScope(form.Scope) {
+		resp = Response{Status: "fail", Msg: "Unknown scope: " + form.Scope}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+	if form.Scope == object.TokenScopeModerate && !object.HasModeratorRight(memberId) {
+		resp = Response{Status: "fail", Msg: "Only moderators can create tokens with the moderate scope."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+	if form.ExpireDays < 0 || form.ExpireDays > object.MaxAccessTokenExpireDays {
+		resp = Response{Status: "fail", Msg: "Invalid expiration."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+	if object.GetMemberValidAccessTokenNum(memberId) >= object.MaxAccessTokenNum {
+		resp = Response{Status: "fail", Msg: "You have too many tokens, please revoke some first."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	token := object.AccessToken{
+		MemberId:    memberId,
+		Name:        form.Name,
+		Scope:       form.Scope,
+		CreatedTime: util.GetCurrentTime(),
+	}
+	if form.ExpireDays != 0 {
+		token.ExpireTime = util.GetTimeDay(form.ExpireDays)
+	}
+
+	res, value := object.AddAccessToken(&token)
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] created access token %d with scope %s", memberId, token.Id, token.Scope)
+		resp = Response{Status: "ok", Msg: "success", Data: value, Data2: token}
+	} else {
+		resp = Response{Status: "error", Msg: "fail"}
+	}
+
+	c.Data["json"] = resp
+	c.ServeJSON()
+}
+
+// DeleteAccessToken revokes the token, it's kept in the list for reference.
+func (c *APIController) DeleteAccessToken() {
+	if c.RequireLogin() {
+		return
+	}
+
+	id := util.ParseInt(c.Input().Get("id"))
+	memberId := c.GetSessionUser()
+
+	c.wrapResponse(object.RevokeAccessToken(memberId, id))
+}
@@ -15,19 +15,9 @@
 package controllers
 
 import (
-	"context"
 	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
 	"net/url"
-	"regexp"
 	"strings"
-	"sync"
-
-	"golang.org/x/oauth2"
-
-	"github.com/astaxie/beego"
 
 	"github.com/casbin/casnode/object"
 	"github.com/casbin/casnode/service"
@@ -49,6 +39,7 @@ type SignupForm struct {
 	ValidateCodeId string `json:"validateCodeId"`
 	Addition       string `json:"addition"`
 	Addition2      string `json:"addition2"` // this field is for more addition info if needed
+	InviteCode     string `json:"inviteCode"`
 }
 
 // SigninForm information field could be phone number, username or email.
@@ -103,7 +94,25 @@ func (c *APIController) Signup() {
 		return
 	}
 
-	if form.Method != "google" && form.Method != "github" && form.Method != "qq" && form.Method != "wechat" {
+	signupMode := object.GetSignupMode()
+	if signupMode == object.SignupModeInvite && !object.CheckInviteCode(form.InviteCode) {
+		resp = Response{Status: "error", Msg: "Invalid invite code"}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	// the identity authenticated by the provider in Auth()
+	identity := c.getSessionIdentity()
+	isIdentityMethod := service.GetIdProvider(form.Method) != nil
+	if isIdentityMethod {
+		if identity == nil || identity.Provider != form.Method {
+			resp = Response{Status: "error", Msg: "Please sign in with " + form.Method + " again", Data: ""}
+			c.Data["json"] = resp
+			c.ServeJSON()
+			return
+		}
+	} else {
 		// Check validate code.
 		var validateCodeRes bool
 		if form.Method == "phone" {
@@ -123,8 +132,11 @@ func (c *APIController) Signup() {
 	}
 
 	member, password, email, avatar := form.Username, form.Password, form.Email, form.Avatar
+	if isIdentityMethod {
+		email, avatar = identity.Email, identity.Avatar
+	}
 
-	if object.HasMember(member) {
+	if object.IsUsernameTaken(member, "") {
 		resp = Response{Status: "error", Msg: "Member already exists"}
 		c.Data["json"] = resp
 		c.ServeJSON()
@@ -155,30 +167,29 @@ func (c *APIController) Signup() {
 			if len(msg) == 0 {
 				msg = object.CheckMemberSignupWithPhone(member, form.Phone)
 			}
-		} else if form.Method == "qq" {
-			// Check the information registered through the qq method.
-			msg = object.CheckMemberSignupWithQQ(member, form.Addition2)
-		} else if form.Method == "wechat" {
-			msg = object.CheckMemberSignupWithWeChat(member, form.Addition2)
+		} else if isIdentityMethod {
+			// Check the information registered through the identity providers.
+			msg = object.CheckMemberSignupWithLinkedAccount(member, identity.Provider, identity.Subject)
+			if len(msg) == 0 && email != "" && object.HasMail(email) != "" {
+				msg = "Username existed or email existed"
+			}
 		}
 	}
 
+	// the code is consumed right before the member is added, so that it's used only once
+	if msg == "" && signupMode == object.SignupModeInvite && !object.UseInviteCode(form.InviteCode, member) {
+		msg = "Invalid invite code"
+	}
+
 	if msg != "" {
 		resp = Response{Status: "error", Msg: msg, Data: ""}
 	} else {
-		if form.Method == "qq" || form.Method == "wechat" {
-			avatar, err = url.QueryUnescape(avatar)
-			if err != nil {
-				panic(err)
-			}
-		}
 		avatar = UploadAvatarToOSS(avatar, member)
 		no := object.GetMemberNum()
 		member := &object.Member{
 			Id:           member,
 			Password:     password,
 			No:           no + 1,
-			IsModerator:  false,
 			CreatedTime:  util.GetCurrentTime(),
 			Phone:        form.Phone,
 			AreaCode:     form.AreaCode,
@@ -190,37 +201,28 @@ func (c *APIController) Signup() {
 			Location:     form.Location,
 			FileQuota:    object.DefaultUploadFileQuota,
 		}
-		if no == 0 {
-			member.IsModerator = true
+		if no != 0 && signupMode == object.SignupModeApproval {
+			member.Status = 4
 		}
 		switch form.Method {
-		case "google":
-			member.GoogleAccount = form.Addition
-			if len(email) != 0 {
-				member.EmailVerifiedTime = util.GetCurrentTime()
-			}
-			break
-		case "github":
-			member.GithubAccount = form.Addition
-			if len(email) != 0 {
-				member.EmailVerifiedTime = util.GetCurrentTime()
-			}
-			break
 		case "phone":
 			member.PhoneVerifiedTime = util.GetCurrentTime()
 		case "email":
 			member.EmailVerifiedTime = util.GetCurrentTime()
-		case "qq":
-			member.QQOpenId = form.Addition2
-			member.QQAccount = form.Addition
-			member.QQVerifiedTime = util.GetCurrentTime()
-		case "wechat":
-			member.WechatOpenId = form.Addition2
-			member.WechatAccount = form.Addition
-			member.WechatVerifiedTime = util.GetCurrentTime()
+		}
+		if isIdentityMethod && identity.EmailVerified && len(email) != 0 {
+			member.EmailVerifiedTime = util.GetCurrentTime()
 		}
 
 		object.AddMember(member)
+		// the first member manages the site
+		if no == 0 {
+			object.AddMemberRole(member.Id, object.RoleAdmin, object.DomainSite)
+		}
+		if isIdentityMethod {
+			object.AddLinkedAccount(newLinkedAccount(identity.Provider, member.Id, &identity.UserInfo))
+			c.setSessionIdentity(nil)
+		}
 
 		c.SetSessionUser(member.Id)
 
@@ -254,20 +256,39 @@ func (c *APIController) Signin() {
 		panic(err)
 	}
 
-	verifyCaptchaRes := object.VerifyCaptcha(form.CaptchaId, form.Captcha)
-	if !verifyCaptchaRes {
-		resp = Response{Status: "error", Msg: "Captcha error"}
+	// failures count against both the account and the IP address
+	target := object.GetSigninTarget(form.Information)
+	ip := util.GetClientIp(c.Ctx.Request)
+	unlockTime := object.GetAuthLockTime(object.AuthActionSignin, target, object.SigninLockFailures)
+	if unlockTime == "" {
+		unlockTime = object.GetAuthLockTime(object.AuthActionSigninIp, ip, object.SigninIpLockFailures)
+	}
+	if unlockTime != "" {
+		resp = Response{Status: "error", Msg: "errorSigninLocked", Data: unlockTime}
 		c.Data["json"] = resp
 		c.ServeJSON()
 		return
 	}
 
+	if c.signinCaptchaRequired(target, ip) {
+		verifyCaptchaRes := object.VerifyCaptcha(form.CaptchaId, form.Captcha)
+		if !verifyCaptchaRes {
+			resp = Response{Status: "error", Msg: "Captcha error"}
+			c.Data["json"] = resp
+			c.ServeJSON()
+			return
+		}
+	}
+
 	var information string
 	var password string
 	information, password = form.Information, form.Password
 	member, msg := object.CheckMemberLogin(information, password)
 
 	if msg != "" {
+		object.AddAuthFailure(object.AuthActionSignin, target)
+		object.AddAuthFailure(object.AuthActionSigninIp, ip)
+		util.LogWarning(c.Ctx, "API: signin of [%s] failed", target)
 		resp = Response{Status: "error", Msg: msg, Data: ""}
 	} else {
 		// check account status
@@ -276,7 +297,13 @@ func (c *APIController) Signin() {
 			return
 		}
 
-		c.SetSessionUser(member)
+		// the failures are cleared after the second factor, so that the password can't reset its lock
+		if !c.signinMember(member) {
+			c.SetSession("twoFactorTarget", target)
+			c.twoFactorRequiredResp(member)
+			return
+		}
+		object.ClearAuthFailures(object.AuthActionSignin, target)
 
 		util.LogInfo(c.Ctx, "API: [%s] signed in", member)
 		resp = Response{Status: "ok", Msg: "success", Data: member}
@@ -286,6 +313,26 @@ func (c *APIController) Signin() {
 	c.ServeJSON()
 }
 
+// signinCaptchaRequired returns whether the account or the IP address has failed to sign in SigninCaptchaFailures times.
+func (c *APIController) signinCaptchaRequired(target, ip string) bool {
+	num, _ := object.GetAuthFailures(object.AuthActionSignin, target)
+	if num >= object.SigninCaptchaFailures {
+		return true
+	}
+
+	num, _ = object.GetAuthFailures(object.AuthActionSigninIp, ip)
+	return num >= object.SigninCaptchaFailures
+}
+
+// GetSigninCaptchaRequired returns whether Signin() needs a captcha for the information.
+func (c *APIController) GetSigninCaptchaRequired() {
+	target := object.GetSigninTarget(c.Input().Get("information"))
+	required := c.signinCaptchaRequired(target, util.GetClientIp(c.Ctx.Request))
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: required}
+	c.ServeJSON()
+}
+
 // @Title Signout
 // @Description sign out the current member
 // @Success 200 {object} controllers.api_controller.Response The Response object
@@ -314,10 +361,13 @@ func (c *APIController) GetAccount() {
 		return
 	}
 
-	var memberObj interface{}
 	username := c.GetSessionUser()
-	memberObj = object.GetMember(username)
-	resp = Response{Status: "ok", Msg: "", Data: util.StructToJson(memberObj)}
+	member := object.GetMember(username)
+	if member != nil {
+		member.LinkedAccounts = object.GetMemberLinkedAccounts(username)
+		member.IsModerator = object.HasMemberRole(username, object.RoleAdmin, object.DomainSite)
+	}
+	resp = Response{Status: "ok", Msg: "", Data: util.StructToJson(member)}
 
 	c.Data["json"] = resp
 	c.ServeJSON()
@@ -414,10 +464,7 @@ func (c *APIController) ResetPassword() {
 			resetId, resetCode := object.AddNewResetRecord(userInfo.Email, form.Username, 2)
 			idStr := util.IntToString(resetId)
 			resetUrl := form.Url + "/forgot?method=email" + "&id=" + idStr + "&code=" + resetCode + "&username=" + userInfo.Id
-			err := service.SendResetPasswordMail(userInfo.Email, userInfo.Id, resetUrl)
-			if err != nil {
-				resp = Response{Status: "error", Msg: "Send email fail"}
-			}
+			object.EnqueueMail(service.NewResetPasswordMail(userInfo.Email, userInfo.Id, resetUrl))
 			resp = Response{Status: "ok", Msg: "success", Data: "email", Data2: userInfo.Email}
 		} else {
 			resp = Response{Status: "error", Msg: "Email and account do not correspond"}
@@ -466,8 +513,10 @@ func (c *APIController) ResetPassword() {
 		userInfo := object.GetMember(form.Username)
 		if userInfo == nil {
 			resp = Response{Status: "error", Msg: "Member not found"}
+		} else if msg := object.CheckValidateCodeQuota(userInfo.Phone, util.GetClientIp(c.Ctx.Request)); msg != "" {
+			resp = Response{Status: "error", Msg: msg}
 		} else {
-			validateCodeId, code := object.GetNewValidateCode(userInfo.Phone)
+			validateCodeId, code := object.GetNewValidateCode(userInfo.Phone, util.GetClientIp(c.Ctx.Request))
 			service.SendSms(userInfo.Phone, code)
 			resp = Response{Status: "ok", Msg: "success", Data: validateCodeId}
 		}
@@ -484,362 +533,58 @@ func (c *APIController) GetSessionId() {
 	c.ServeJSON()
 }
 
-var googleEndpoint = oauth2.Endpoint{
-	AuthURL:  "https://accounts.google.com/o/oauth2/auth",
-	TokenURL: "https://accounts.google.com/o/oauth2/token",
-}
-
-var googleOauthConfig = &oauth2.Config{
-	ClientID:     beego.AppConfig.String("GoogleAuthClientID"),
-	ClientSecret: beego.AppConfig.String("GoogleAuthClientSecret"),
-	RedirectURL:  "",
-	Scopes:       []string{"profile", "email"},
-	Endpoint:     googleEndpoint,
-}
-
-//Using addition to judge signup or link
-func (c *APIController) AuthGoogle() {
+// Auth handles the callback of the identity provider in the url, e.g. /api/auth/github.
+// Using addition to judge signup or link.
+func (c *APIController) Auth() {
+	providerName := c.Ctx.Input.Param(":provider")
 	code := c.Input().Get("code")
 	state := c.Input().Get("state")
 	addition := c.Input().Get("addition")
-	RedirectURL := c.Input().Get("redirect_url")
+	redirectUrl := c.Input().Get("redirect_url")
 
 	var resp Response
 	var res authResponse
 	res.IsAuthenticated = true
 
-	if state != beego.AppConfig.String("GoogleAuthState") {
-		res.IsAuthenticated = false
-		resp = Response{Status: "fail", Msg: "unauthorized", Data: res}
+	idProvider := service.GetIdProvider(providerName)
+	if idProvider == nil {
+		resp = Response{Status: "fail", Msg: "Unknown identity provider: " + providerName}
 		c.Data["json"] = resp
 		c.ServeJSON()
 		return
 	}
 
-	googleOauthConfig.RedirectURL = RedirectURL
-
-	// https://github.com/golang/oauth2/issues/123#issuecomment-103715338
-	ctx := context.WithValue(oauth2.NoContext, oauth2.HTTPClient, httpClient)
-	token, err := googleOauthConfig.Exchange(ctx, code)
-	if err != nil {
-		res.IsAuthenticated = false
-		panic(err)
-	}
-
-	response, err := httpClient.Get("https://www.googleapis.com/oauth2/v2/userinfo?alt=json&access_token=" + token.AccessToken)
-	defer response.Body.Close()
-	contents, err := ioutil.ReadAll(response.Body)
-	var tempUser userInfoFromGoogle
-	err = json.Unmarshal(contents, &tempUser)
-	if err != nil {
-		res.IsAuthenticated = false
-		panic(err)
-	}
-	res.Email = tempUser.Email
-	res.Avatar = tempUser.Picture
-
-	if res.Email == "" {
-		resp = Response{Status: "fail", Msg: "Login failed, please try again."}
-		c.Data["json"] = resp
-		c.ServeJSON()
-		return
-	}
-
-	if addition == "signup" {
-		userId := object.HasGoogleAccount(res.Email)
-		if userId != "" {
-			// check account status
-			if object.IsForbidden(userId) {
-				c.forbiddenAccountResp(userId)
-				return
-			}
-
-			if len(object.GetMemberAvatar(userId)) == 0 {
-				avatar := UploadAvatarToOSS(res.Avatar, userId)
-				object.LinkMemberAccount(userId, "avatar", avatar)
-			}
-			c.SetSessionUser(userId)
-			util.LogInfo(c.Ctx, "API: [%s] signed in", userId)
-			res.IsSignedUp = true
-		} else {
-			if userId := object.HasMail(res.Email); userId != "" {
-				// check account status
-				if object.IsForbidden(userId) {
-					c.forbiddenAccountResp(userId)
-					return
-				}
-
-				c.SetSessionUser(userId)
-				util.LogInfo(c.Ctx, "API: [%s] signed in", userId)
-				res.IsSignedUp = true
-				_ = object.LinkMemberAccount(userId, "google_account", tempUser.Email)
-			} else {
-				res.IsSignedUp = false
-			}
-		}
-		res.Addition = res.Email
-		resp = Response{Status: "ok", Msg: "success", Data: res}
-	} else {
-		memberId := c.GetSessionUser()
-		if memberId == "" {
-			resp = Response{Status: "fail", Msg: "no account exist", Data: res}
-			c.Data["json"] = resp
-			c.ServeJSON()
-			return
-		}
-		linkRes := object.LinkMemberAccount(memberId, "google_account", res.Email)
-		if linkRes {
-			resp = Response{Status: "ok", Msg: "success", Data: linkRes}
-		} else {
-			resp = Response{Status: "fail", Msg: "link account failed", Data: linkRes}
-		}
-		if len(object.GetMemberAvatar(memberId)) == 0 {
-			avatar := UploadAvatarToOSS(res.Avatar, memberId)
-			object.LinkMemberAccount(memberId, "avatar", avatar)
-		}
-	}
-
-	c.Data["json"] = resp
-
-	c.ServeJSON()
-}
-
-var githubEndpoint = oauth2.Endpoint{
-	AuthURL:  "https://github.com/login/oauth/authorize",
-	TokenURL: "https://github.com/login/oauth/access_token",
-}
-
-var githubOauthConfig = &oauth2.Config{
-	ClientID:     beego.AppConfig.String("GithubAuthClientID"),
-	ClientSecret: beego.AppConfig.String("GithubAuthClientSecret"),
-	RedirectURL:  "",
-	Scopes:       []string{"user:email", "read:user"},
-	Endpoint:     githubEndpoint,
-}
-
-func (c *APIController) AuthGithub() {
-	code := c.Input().Get("code")
-	state := c.Input().Get("state")
-	addition := c.Input().Get("addition")
-	RedirectURL := c.Input().Get("redirect_url")
-
-	var resp Response
-	var res authResponse
-	res.IsAuthenticated = true
-
-	if state != beego.AppConfig.String("GithubAuthState") {
+	if state != idProvider.GetConfig().State {
 		res.IsAuthenticated = false
 		resp = Response{Status: "fail", Msg: "unauthorized", Data: res}
+		c.Data["json"] = resp
 		c.ServeJSON()
 		return
 	}
 
-	githubOauthConfig.RedirectURL = RedirectURL
-
-	// https://github.com/golang/oauth2/issues/123#issuecomment-103715338
-	ctx := context.WithValue(oauth2.NoContext, oauth2.HTTPClient, httpClient)
-	token, err := githubOauthConfig.Exchange(ctx, code)
+	userInfo, err := idProvider.GetUserInfo(code, redirectUrl)
 	if err != nil {
+		util.LogWarning(c.Ctx, "API: %s auth failed: %s", providerName, err.Error())
 		res.IsAuthenticated = false
-		panic(err)
-	}
-
-	if !token.Valid() {
-		resp = Response{Status: "fail", Msg: "unauthorized", Data: res}
-		c.Data["json"] = resp
-		c.ServeJSON()
-		return
-	}
-
-	var wg sync.WaitGroup
-	var tempUserEmail []userEmailFromGithub
-	var tempUserAccount userInfoFromGithub
-	wg.Add(2)
-	go func() {
-		req, _ := http.NewRequest("GET", "https://api.github.com/user/emails", nil)
-		req.Header.Set("Authorization", "token "+token.AccessToken)
-		response, err := httpClient.Do(req)
-		if err != nil {
-			panic(err)
-		}
-		defer response.Body.Close()
-		contents, err := ioutil.ReadAll(response.Body)
-
-		err = json.Unmarshal(contents, &tempUserEmail)
-		if err != nil {
-			res.IsAuthenticated = false
-			panic(err)
-		}
-		for _, v := range tempUserEmail {
-			if v.Primary == true {
-				res.Email = v.Email
-				break
-			}
-		}
-		wg.Done()
-	}()
-	go func() {
-		req, _ := http.NewRequest("GET", "https://api.github.com/user", nil)
-		req.Header.Set("Authorization", "token "+token.AccessToken)
-		response2, err := httpClient.Do(req)
-		if err != nil {
-			panic(err)
-		}
-		defer response2.Body.Close()
-		contents2, err := ioutil.ReadAll(response2.Body)
-		err = json.Unmarshal(contents2, &tempUserAccount)
-		if err != nil {
-			res.IsAuthenticated = false
-			panic(err)
-		}
-		wg.Done()
-	}()
-	wg.Wait()
-
-	if res.Email == "" || tempUserAccount.Login == "" {
-		resp = Response{Status: "fail", Msg: "Login failed, please try again."}
+		resp = Response{Status: "fail", Msg: "Login failed, please try again.", Data: res}
 		c.Data["json"] = resp
 		c.ServeJSON()
 		return
 	}
+	res.Email = userInfo.Email
+	res.Avatar = url.QueryEscape(userInfo.Avatar)
+	res.Addition = userInfo.Username
 
 	if addition == "signup" {
-		userId := object.HasGithubAccount(tempUserAccount.Login)
-		if userId != "" {
-			// check account status
-			if object.IsForbidden(userId) {
-				c.forbiddenAccountResp(userId)
-				return
-			}
-
-			if len(object.GetMemberAvatar(userId)) == 0 {
-				avatar := UploadAvatarToOSS(tempUserAccount.AvatarUrl, userId)
-				object.LinkMemberAccount(userId, "avatar", avatar)
-			}
-			c.SetSessionUser(userId)
-			util.LogInfo(c.Ctx, "API: [%s] signed in", userId)
-			res.IsSignedUp = true
-		} else {
-			if userId := object.HasMail(res.Email); userId != "" {
-				// check account status
-				if object.IsForbidden(userId) {
-					c.forbiddenAccountResp(userId)
-					return
-				}
-
-				c.SetSessionUser(userId)
-				util.LogInfo(c.Ctx, "API: [%s] signed in", userId)
-				res.IsSignedUp = true
-				_ = object.LinkMemberAccount(userId, "github_account", tempUserAccount.Login)
-			} else {
-				res.IsSignedUp = false
-			}
-		}
-		res.Addition = tempUserAccount.Login
-		res.Avatar = tempUserAccount.AvatarUrl
-		resp = Response{Status: "ok", Msg: "success", Data: res}
-	} else {
-		memberId := c.GetSessionUser()
-		if memberId == "" {
-			resp = Response{Status: "fail", Msg: "no account exist", Data: res}
+		userId := object.GetIdentityMember(providerName, userInfo.Subject, userInfo.LegacySubject)
+		if userId == "" && userInfo.Email != "" && object.HasMail(userInfo.Email) != "" {
+			// the identity isn't linked by the email, the member has to sign in and link it
+			resp = Response{Status: "fail", Msg: "The email has been used by an account, please sign in and link your " + providerName + " account in the settings.", Data: res}
 			c.Data["json"] = resp
 			c.ServeJSON()
 			return
 		}
-		linkRes := object.LinkMemberAccount(memberId, "github_account", tempUserAccount.Login)
-		if linkRes {
-			resp = Response{Status: "ok", Msg: "success", Data: linkRes}
-		} else {
-			resp = Response{Status: "fail", Msg: "link account failed", Data: linkRes}
-		}
-		if len(object.GetMemberAvatar(memberId)) == 0 {
-			avatar := UploadAvatarToOSS(tempUserAccount.AvatarUrl, memberId)
-			object.LinkMemberAccount(memberId, "avatar", avatar)
-		}
-	}
-
-	c.Data["json"] = resp
-
-	c.ServeJSON()
-}
-
-var QQClientID = beego.AppConfig.String("QQAPPID")
-var QQClientSecret = beego.AppConfig.String("QQAPPKey")
-
-func (c *APIController) AuthQQ() {
-	code := c.Input().Get("code")
-	state := c.Input().Get("state")
-	addition := c.Input().Get("addition")
-	redirectURL := c.Input().Get("redirect_url")
-
-	var resp Response
-	var res authResponse
-	res.IsAuthenticated = true
-
-	if state != beego.AppConfig.String("QQAuthState") {
-		res.IsAuthenticated = false
-		resp = Response{Status: "fail", Msg: "unauthorized", Data: res}
-		c.ServeJSON()
-		return
-	}
-
-	params := url.Values{}
-	params.Add("grant_type", "authorization_code")
-	params.Add("client_id", QQClientID)
-	params.Add("client_secret", QQClientSecret)
-	params.Add("code", code)
-	params.Add("redirect_uri", redirectURL)
-	getAccessKeyUrl := fmt.Sprintf("%s?%s", "https://graph.qq.com/oauth2.0/token", params.Encode())
-
-	tokenResponse, err := httpClient.Get(getAccessKeyUrl)
-	if err != nil {
-		panic(err)
-	}
-	defer tokenResponse.Body.Close()
-	tokenContent, err := ioutil.ReadAll(tokenResponse.Body)
-
-	tokenReg := regexp.MustCompile("token=(.*?)&")
-	tokenRegRes := tokenReg.FindAllStringSubmatch(string(tokenContent), -1)
-	token := tokenRegRes[0][1]
-
-	getOpenIdUrl := fmt.Sprintf("https://graph.qq.com/oauth2.0/me?access_token=%s", token)
-
-	openIdResponse, err := httpClient.Get(getOpenIdUrl)
-	if err != nil {
-		panic(err)
-	}
-	defer openIdResponse.Body.Close()
-	openIdContent, err := ioutil.ReadAll(openIdResponse.Body)
-
-	openIdReg := regexp.MustCompile("\"openid\":\"(.*?)\"}")
-	openIdRegRes := openIdReg.FindAllStringSubmatch(string(openIdContent), -1)
-	openId := openIdRegRes[0][1]
-
-	getUserInfoUrl := fmt.Sprintf("https://graph.qq.com/user/get_user_info?access_token=%s&oauth_consumer_key=%s&openid=%s", token, QQClientID, openId)
-	getUserInfoResponse, err := httpClient.Get(getUserInfoUrl)
-	if err != nil {
-		panic(err)
-	}
-	defer getUserInfoResponse.Body.Close()
-	userInfoContent, err := ioutil.ReadAll(getUserInfoResponse.Body)
-	var userInfo userInfoFromQQ
-	err = json.Unmarshal(userInfoContent, &userInfo)
-	if err != nil || userInfo.Ret != 0 {
-		res.IsAuthenticated = false
-		if err != nil {
-			panic(err)
-		}
-	}
 
-	if openId == "" {
-		resp = Response{Status: "fail", Msg: "Login failed, please try again."}
-		c.Data["json"] = resp
-		c.ServeJSON()
-		return
-	}
-
-	if addition == "signup" {
-		userId := object.HasQQAccount(openId)
 		if userId != "" {
 			// check account status
 			if object.IsForbidden(userId) {
@@ -848,18 +593,21 @@ func (c *APIController) AuthQQ() {
 			}
 
 			if len(object.GetMemberAvatar(userId)) == 0 {
-				avatar := UploadAvatarToOSS(userInfo.AvatarUrl, userId)
+				avatar := UploadAvatarToOSS(userInfo.Avatar, userId)
 				object.LinkMemberAccount(userId, "avatar", avatar)
 			}
-			c.SetSessionUser(userId)
+			if !c.signinMember(userId) {
+				c.twoFactorRequiredResp(userId)
+				return
+			}
 			util.LogInfo(c.Ctx, "API: [%s] signed in", userId)
 			res.IsSignedUp = true
 		} else {
+			// keep the identity until the member chooses a username and signs up
+			c.setSessionIdentity(&linkedIdentity{Provider: providerName, UserInfo: *userInfo})
 			res.IsSignedUp = false
 		}
-		res.Addition = userInfo.Nickname
-		res.Avatar = url.QueryEscape(userInfo.AvatarUrl)
-		resp = Response{Status: "ok", Msg: "success", Data: res, Data2: openId}
+		resp = Response{Status: "ok", Msg: "success", Data: res, Data2: userInfo.Subject}
 	} else {
 		memberId := c.GetSessionUser()
 		if memberId == "" {
@@ -868,129 +616,22 @@ func (c *APIController) AuthQQ() {
 			c.ServeJSON()
 			return
 		}
-		linkRes := object.LinkMemberAccount(memberId, "qq_account", userInfo.Nickname)
-		linkRes = object.LinkMemberAccount(memberId, "qq_open_id", openId)
-		linkRes = object.LinkMemberAccount(memberId, "qq_verified_time", util.GetCurrentTime())
-		if linkRes {
-			resp = Response{Status: "ok", Msg: "success", Data: linkRes}
-		} else {
-			resp = Response{Status: "fail", Msg: "link account failed", Data: linkRes}
-		}
-		if len(object.GetMemberAvatar(memberId)) == 0 {
-			avatar := UploadAvatarToOSS(userInfo.AvatarUrl, memberId)
-			object.LinkMemberAccount(memberId, "avatar", avatar)
-		}
-	}
-
-	c.Data["json"] = resp
 
-	c.ServeJSON()
-}
-
-var WeChatClientID = beego.AppConfig.String("WeChatAPPID")
-var WeChatClientSecret = beego.AppConfig.String("WeChatKey")
-
-func (c *APIController) AuthWeChat() {
-	code := c.Input().Get("code")
-	state := c.Input().Get("state")
-	addition := c.Input().Get("addition")
-	//redirectURL := c.Input().Get("redirect_url")
-
-	var resp Response
-	var res authResponse
-	res.IsAuthenticated = true
-
-	if state != beego.AppConfig.String("WeChatAuthState") {
-		res.IsAuthenticated = false
-		resp = Response{Status: "fail", Msg: "unauthorized", Data: res}
-		c.ServeJSON()
-		return
-	}
-
-	params := url.Values{}
-	params.Add("code", code)
-	params.Add("grant_type", "authorization_code")
-	params.Add("appid", WeChatClientID)
-	params.Add("secret", WeChatClientSecret)
-
-	getAccessKeyUrl := fmt.Sprintf("%s?%s", "https://api.weixin.qq.com/sns/oauth2/access_token", params.Encode())
-
-	tokenResponse, err := httpClient.Get(getAccessKeyUrl)
-	if err != nil {
-		panic(err)
-	}
-	defer tokenResponse.Body.Close()
-	tokenContent, err := ioutil.ReadAll(tokenResponse.Body)
-	if err != nil {
-		panic(err)
-	}
-	var accessTokenResp *GetAccessTokenRespFromWeChat
-	err = json.Unmarshal(tokenContent, &accessTokenResp)
-	if err != nil {
-		panic(err)
-	}
-	token := accessTokenResp.AccessToken
-	openid := accessTokenResp.Openid
-
-	getUserInfoUrl := fmt.Sprintf("https://api.weixin.qq.com/sns/userinfo?access_token=%s&openid=%s", token, openid)
-	getUserInfoResponse, err := httpClient.Get(getUserInfoUrl)
-	if err != nil {
-		panic(err)
-	}
-	defer getUserInfoResponse.Body.Close()
-	userInfoContent, err := ioutil.ReadAll(getUserInfoResponse.Body)
-	var userInfo userInfoFromWeChat
-	err = json.Unmarshal(userInfoContent, &userInfo)
-	if err != nil {
-		res.IsAuthenticated = false // unexpected return
-		panic(err)
-	}
-
-	if openid == "" {
-		resp = Response{Status: "fail", Msg: "Login failed, please try again."}
-		c.Data["json"] = resp
-		c.ServeJSON()
-		return
-	}
-
-	if addition == "signup" {
-		userId := object.HasWeChatAccount(openid)
-		if userId != "" {
-			if object.IsForbidden(userId) {
-				c.forbiddenAccountResp(userId)
-				return
-			}
-			if len(object.GetMemberAvatar(userId)) == 0 {
-				avatar := UploadAvatarToOSS(userInfo.AvatarUrl, userId)
-				object.LinkMemberAccount(userId, "avatar", avatar)
-			}
-			c.SetSessionUser(userId)
-			util.LogInfo(c.Ctx, "API: [%s] signed in", userId)
-			res.IsSignedUp = true
-		} else {
-			res.IsSignedUp = false
-		}
-		res.Addition = userInfo.Nickname
-		res.Avatar = url.QueryEscape(userInfo.AvatarUrl)
-		resp = Response{Status: "ok", Msg: "success", Data: res, Data2: openid}
-	} else {
-		memberId := c.GetSessionUser()
-		if memberId == "" {
-			resp = Response{Status: "fail", Msg: "no account exist", Data: res}
+		if linkedMember := object.GetIdentityMember(providerName, userInfo.Subject, userInfo.LegacySubject); linkedMember != "" && linkedMember != memberId {
+			resp = Response{Status: "fail", Msg: "This " + providerName + " account has already been linked with another account"}
 			c.Data["json"] = resp
 			c.ServeJSON()
 			return
 		}
-		linkRes := object.LinkMemberAccount(memberId, "wechat_account", userInfo.Nickname)
-		linkRes = object.LinkMemberAccount(memberId, "wechat_open_id", openid)
-		linkRes = object.LinkMemberAccount(memberId, "wechat_verified_time", util.GetCurrentTime())
+
+		linkRes := object.AddLinkedAccount(newLinkedAccount(providerName, memberId, userInfo))
 		if linkRes {
 			resp = Response{Status: "ok", Msg: "success", Data: linkRes}
 		} else {
 			resp = Response{Status: "fail", Msg: "link account failed", Data: linkRes}
 		}
 		if len(object.GetMemberAvatar(memberId)) == 0 {
-			avatar := UploadAvatarToOSS(userInfo.AvatarUrl, memberId)
+			avatar := UploadAvatarToOSS(userInfo.Avatar, memberId)
 			object.LinkMemberAccount(memberId, "avatar", avatar)
 		}
 	}
@@ -999,3 +640,21 @@ func (c *APIController) AuthWeChat() {
 
 	c.ServeJSON()
 }
+
+// GetAuthProviders returns the configured identity providers.
+func (c *APIController) GetAuthProviders() {
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: service.GetIdProviderInfos()}
+	c.ServeJSON()
+}
+
+func newLinkedAccount(provider, memberId string, userInfo *service.UserInfo) *object.LinkedAccount {
+	return &object.LinkedAccount{
+		Provider:    provider,
+		Subject:     userInfo.Subject,
+		MemberId:    memberId,
+		Username:    userInfo.Username,
+		Email:       userInfo.Email,
+		Avatar:      userInfo.Avatar,
+		CreatedTime: util.GetCurrentTime(),
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/util"
+)
+
+// getPage returns the limit and offset of the requested page.
+func (c *APIController) getPage() (int, int) {
+	limitStr := c.Input().Get("limit")
+	pageStr := c.Input().Get("page")
+
+	limit := object.DefaultPageNum
+	if len(limitStr) != 0 {
+		limit = util.ParseInt(limitStr)
+		if limit <= 0 || limit > object.DefaultMemberAdminPageNum {
+			limit = object.DefaultPageNum
+		}
+	}
+	offset := 0
+	if len(pageStr) != 0 {
+		page := util.ParseInt(pageStr)
+		if page > 1 {
+			offset = page*limit - limit
+		}
+	}
+
+	return limit, offset
+}
+
+// GetMemberActivities returns the timeline of the member: topics, replies, and the thanks, favorite nodes
+// and followed members if the member has made them public.
+func (c *APIController) GetMemberActivities() {
+	id := object.ResolveMemberId(c.Input().Get("id"))
+	limit, offset := c.getPage()
+
+	res := object.GetMemberActivities(id, c.GetSessionUser(), limit, offset)
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: res}
+	c.ServeJSON()
+}
+
+// GetFollowingActivities returns the timeline of the members followed by the current member.
+func (c *APIController) GetFollowingActivities() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	limit, offset := c.getPage()
+
+	res := object.GetFollowingActivities(memberId, limit, offset)
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: res}
+	c.ServeJSON()
+}
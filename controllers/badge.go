@@ -0,0 +1,194 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/util"
+)
+
+func (c *APIController) GetBadges() {
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetBadges()}
+	c.ServeJSON()
+}
+
+// checkBadge returns the error message if the badge can't be saved.
+func checkBadge(badge *object.Badge) string {
+	if badge.Id == "" || badge.Name == "" {
+		return "Some information is missing"
+	}
+	if !object.IsValidBadgeRule(badge.Rule) {
+		return "Invalid badge rule."
+	}
+	if badge.Rule != "" && badge.Threshold <= 0 {
+		return "The threshold of the rule should be positive."
+	}
+
+	return ""
+}
+
+func (c *APIController) AddBadge() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	var badge object.Badge
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &badge)
+	if err != nil {
+		panic(err)
+	}
+
+	var resp Response
+	if msg := checkBadge(&badge); msg != "" {
+		resp = Response{Status: "fail", Msg: msg}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+	if object.HasBadge(badge.Id) {
+		resp = Response{Status: "fail", Msg: "Badge ID existed"}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	badge.CreatedTime = util.GetCurrentTime()
+	res := object.AddBadge(&badge)
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] added badge %s", memberId, badge.Id)
+	}
+
+	c.wrapResponse(res)
+}
+
+func (c *APIController) UpdateBadge() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	id := c.Input().Get("id")
+	var badge object.Badge
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &badge)
+	if err != nil {
+		panic(err)
+	}
+
+	badge.Id = id
+	if msg := checkBadge(&badge); msg != "" {
+		resp := Response{Status: "fail", Msg: msg}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	c.wrapResponse(object.UpdateBadge(id, &badge))
+}
+
+func (c *APIController) DeleteBadge() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	id := c.Input().Get("id")
+	res := object.DeleteBadge(id)
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] deleted badge %s", memberId, id)
+	}
+
+	c.wrapResponse(res)
+}
+
+// GetMemberBadges returns the badges shown on the member's profile, members see their hidden badges too.
+func (c *APIController) GetMemberBadges() {
+	id := c.Input().Get("id")
+	memberId := c.GetSessionUser()
+	if id == "" {
+		id = memberId
+	}
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetMemberBadges(id, id == memberId)}
+	c.ServeJSON()
+}
+
+// GrantBadge grants the badge to the member manually.
+func (c *APIController) GrantBadge() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	var form memberBadgeForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	if !object.HasMember(form.MemberId) || !object.HasBadge(form.BadgeId) {
+		resp := Response{Status: "fail", Msg: "Member or badge doesn't exist."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	res := object.AwardBadge(form.MemberId, form.BadgeId, memberId)
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] granted badge %s to [%s]", memberId, form.BadgeId, form.MemberId)
+	}
+
+	c.wrapResponse(res)
+}
+
+func (c *APIController) RevokeBadge() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	var form memberBadgeForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	res := object.RevokeBadge(form.MemberId, form.BadgeId)
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] revoked badge %s from [%s]", memberId, form.BadgeId, form.MemberId)
+	}
+
+	c.wrapResponse(res)
+}
+
+// UpdateMemberBadgeVisibility shows or hides one of the member's badges on the profile.
+func (c *APIController) UpdateMemberBadgeVisibility() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	id := c.Input().Get("id")
+	hidden := c.Input().Get("hidden") == "true"
+
+	c.wrapResponse(object.UpdateMemberBadgeHidden(memberId, id, hidden))
+}
@@ -35,6 +35,13 @@ func (c *APIController) AddThanks() {
 		author = object.GetTopicAuthor(id)
 	}
 
+	if object.IsMemberBlocked(author, memberId) {
+		resp := Response{Status: "fail", Msg: "The member doesn't accept your thanks."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
 	consumerRecord := object.ConsumptionRecord{
 		//Id:          util.IntToString(object.GetConsumptionRecordId()),
 		ConsumerId:  author,
@@ -81,6 +88,7 @@ func (c *APIController) AddThanks() {
 		if thanksType == "2" {
 			object.AddReplyThanksNum(id)
 		}
+		object.AddThanksNotification(memberId, author, id, thanksType == "2")
 
 		resp = Response{Status: "ok", Msg: "success"}
 	} else {
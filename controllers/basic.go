@@ -15,26 +15,140 @@
 package controllers
 
 import (
+	"encoding/json"
+
 	"github.com/astaxie/beego"
 
 	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/util"
 )
 
 type APIController struct {
 	beego.Controller
 }
 
+// GetSessionUser returns the signed in member, or the owner of the access token in the Authorization header.
 func (c *APIController) GetSessionUser() string {
 	user := c.GetSession("username")
-	if user == nil {
+	if user == nil || user.(string) == "" {
+		token := c.getRequestAccessToken()
+		if token == nil {
+			return ""
+		}
+		return token.MemberId
+	}
+
+	if !c.checkSessionRecord(user.(string)) {
 		return ""
 	}
 
 	return user.(string)
 }
 
+// SetSessionUser signs in the member with a new session id and records it, or signs out with an empty user.
 func (c *APIController) SetSessionUser(user string) {
+	if user == "" {
+		object.DeleteSessionRecord(c.CruSession.SessionID())
+		c.SetSession("username", "")
+		return
+	}
+
+	c.SessionRegenerateID()
 	c.SetSession("username", user)
+	c.SetSession("authTime", util.GetCurrentTime())
+	c.addSessionRecord(user)
+}
+
+// recentlyAuthenticated returns whether the member signed in within ReauthExpiredTime minutes.
+func (c *APIController) recentlyAuthenticated() bool {
+	authTime, ok := c.GetSession("authTime").(string)
+	return ok && authTime != "" && authTime >= util.GetTimeMinute(-object.ReauthExpiredTime)
+}
+
+func (c *APIController) addSessionRecord(user string) {
+	record := object.SessionRecord{
+		SessionKey:   c.CruSession.SessionID(),
+		MemberId:     user,
+		Ip:           util.GetClientIp(c.Ctx.Request),
+		UserAgent:    c.Ctx.Request.UserAgent(),
+		CreatedTime:  util.GetCurrentTime(),
+		LastSeenTime: util.GetCurrentTime(),
+	}
+	object.AddSessionRecord(&record)
+	c.SetSession("sessionRecorded", true)
+}
+
+// checkSessionRecord returns false and signs out the session if it has been revoked.
+// Sessions signed in before the records existed get one on their first request.
+func (c *APIController) checkSessionRecord(user string) bool {
+	if c.Ctx.Input.GetData("sessionChecked") != nil {
+		return true
+	}
+
+	valid := object.TouchSessionRecord(c.CruSession.SessionID(), user, util.GetClientIp(c.Ctx.Request))
+	if !valid {
+		recorded, _ := c.GetSession("sessionRecorded").(bool)
+		if !recorded && object.HasMember(user) && object.GetMemberSessionRevokedTime(user) == "" {
+			c.addSessionRecord(user)
+			valid = true
+		}
+	}
+
+	if !valid {
+		c.SetSession("username", "")
+		return false
+	}
+
+	c.Ctx.Input.SetData("sessionChecked", true)
+	return true
+}
+
+// signinMember signs in the member. If the member has enabled two-factor authentication,
+// the sign in is kept pending until SigninTwoFactor() and false is returned.
+func (c *APIController) signinMember(memberId string) bool {
+	if object.GetMemberTotpEnabled(memberId) {
+		c.SetSession("twoFactorMember", memberId)
+		c.SetSession("twoFactorTime", util.GetCurrentTime())
+		c.SetSession("twoFactorAttempts", 0)
+		c.DelSession("twoFactorTarget")
+		return false
+	}
+
+	c.SetSessionUser(memberId)
+	return true
+}
+
+func (c *APIController) twoFactorRequiredResp(memberId string) {
+	resp := Response{Status: "error", Msg: "errorTwoFactorRequired", Data: memberId}
+	c.Data["json"] = resp
+	c.ServeJSON()
+}
+
+func (c *APIController) getSessionIdentity() *linkedIdentity {
+	data := c.GetSession("identity")
+	if data == nil || data.(string) == "" {
+		return nil
+	}
+
+	var identity linkedIdentity
+	err := json.Unmarshal([]byte(data.(string)), &identity)
+	if err != nil {
+		panic(err)
+	}
+	return &identity
+}
+
+func (c *APIController) setSessionIdentity(identity *linkedIdentity) {
+	if identity == nil {
+		c.SetSession("identity", "")
+		return
+	}
+
+	data, err := json.Marshal(identity)
+	if err != nil {
+		panic(err)
+	}
+	c.SetSession("identity", string(data))
 }
 
 func (c *APIController) RequireLogin() bool {
@@ -68,6 +182,18 @@ func (c *APIController) mutedAccountResp(memberId string) {
 	c.ServeJSON()
 }
 
+func (c *APIController) nodeMutedAccountResp(memberId string) {
+	resp := Response{Status: "error", Msg: "Your account has been muted in this node", Data: memberId}
+	c.Data["json"] = resp
+	c.ServeJSON()
+}
+
+func (c *APIController) pendingAccountResp(memberId string) {
+	resp := Response{Status: "error", Msg: "Your account is waiting for the approval of admin", Data: memberId}
+	c.Data["json"] = resp
+	c.ServeJSON()
+}
+
 func (c *APIController) forbiddenAccountResp(memberId string) {
 	resp := Response{Status: "error", Msg: "Your account has been forbidden to log in", Data: memberId}
 	c.Data["json"] = resp
@@ -0,0 +1,172 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/mail"
+	"strings"
+
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/service"
+	"github.com/casbin/casnode/util"
+)
+
+// checkContactChange returns the error message if the member can't change the email or phone number to the value.
+func checkContactChange(memberId string, form *contactChangeForm) string {
+	switch form.Type {
+	case "email":
+		if _, err := mail.ParseAddress(form.Value); err != nil || strings.ContainsAny(form.Value, " <>") {
+			return "Invalid email address"
+		}
+		if id := object.HasMail(form.Value); id != "" {
+			if id == memberId {
+				return "This is already your email address"
+			}
+			return "This email address has already been linked with another account"
+		}
+	case "phone":
+		if form.Value == "" || strings.ContainsAny(form.Value, " +-") {
+			return "Invalid phone number"
+		}
+		if id := object.HasPhone(form.Value); id != "" {
+			if id == memberId {
+				return "This is already your phone number"
+			}
+			return "This phone number has already been linked with another account"
+		}
+	default:
+		return "Unknown contact type: " + form.Type
+	}
+
+	return ""
+}
+
+// RequestContactChange sends a validate code to the new email address or phone number.
+func (c *APIController) RequestContactChange() {
+	if c.RequireLogin() {
+		return
+	}
+
+	var form contactChangeForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	var resp Response
+	memberId := c.GetSessionUser()
+	form.Value = strings.TrimSpace(form.Value)
+	if msg := checkContactChange(memberId, &form); msg != "" {
+		resp = Response{Status: "fail", Msg: msg}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	ip := util.GetClientIp(c.Ctx.Request)
+	if msg := object.CheckValidateCodeQuota(form.Value, ip); msg != "" {
+		resp = Response{Status: "fail", Msg: msg}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	id, code := object.GetNewValidateCode(form.Value, ip)
+	if form.Type == "phone" {
+		service.SendSms(form.Value, code)
+	} else {
+		object.EnqueueMail(service.NewContactChangeMail(form.Value, memberId, code))
+	}
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: id}
+	c.ServeJSON()
+}
+
+// ConfirmContactChange changes the email address or phone number after verifying the password, or a recent sign in
+// if there is no password, and the validate code, and notifies the old email address.
+func (c *APIController) ConfirmContactChange() {
+	if c.RequireLogin() {
+		return
+	}
+
+	var form contactChangeForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	var resp Response
+	memberId := c.GetSessionUser()
+	form.Value = strings.TrimSpace(form.Value)
+	if object.IsPasswordCorrect(memberId, "") {
+		// members without a password sign in again with a linked account instead
+		if !c.recentlyAuthenticated() {
+			resp = Response{Status: "fail", Msg: "errorReauthRequired"}
+			c.Data["json"] = resp
+			c.ServeJSON()
+			return
+		}
+	} else {
+		// failures count against the account like the ones of signing in
+		target := object.GetSigninTarget(memberId)
+		if unlockTime := object.GetAuthLockTime(object.AuthActionSignin, target, object.SigninLockFailures); unlockTime != "" {
+			resp = Response{Status: "error", Msg: "errorSigninLocked", Data: unlockTime}
+			c.Data["json"] = resp
+			c.ServeJSON()
+			return
+		}
+		if form.Password == "" || !object.IsPasswordCorrect(memberId, form.Password) {
+			object.AddAuthFailure(object.AuthActionSignin, target)
+			util.LogWarning(c.Ctx, "API: password of [%s] failed when changing %s", memberId, form.Type)
+			resp = Response{Status: "fail", Msg: "Password error"}
+			c.Data["json"] = resp
+			c.ServeJSON()
+			return
+		}
+	}
+	if msg := checkContactChange(memberId, &form); msg != "" {
+		resp = Response{Status: "fail", Msg: msg}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+	if !object.VerifyValidateCode(form.ValidateCodeId, form.ValidateCode, form.Value) {
+		resp = Response{Status: "fail", Msg: "validate code error"}
+		if object.CheckValidateCodeExpired(form.ValidateCodeId) {
+			resp = Response{Status: "fail", Msg: "validate code expired"}
+		}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	member := object.GetMember(memberId)
+	var res bool
+	if form.Type == "phone" {
+		res = object.UpdateMemberPhone(memberId, form.Value, form.AreaCode)
+	} else {
+		res = object.UpdateMemberEmail(memberId, form.Value)
+	}
+
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] changed %s", memberId, form.Type)
+		if member.Email != "" && member.EmailVerifiedTime != "" {
+			object.EnqueueMail(service.NewContactChangedMail(member.Email, memberId, form.Type, form.Value))
+		}
+	}
+
+	c.wrapResponse(res)
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/util"
+)
+
+// writeEvent writes the event in the server-sent events format.
+func (c *APIController) writeEvent(event *object.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		panic(err)
+	}
+
+	message := ""
+	if event.Id != 0 {
+		message += fmt.Sprintf("id: %d\n", event.Id)
+	}
+	message += fmt.Sprintf("event: %s\ndata: %s\n\n", event.Type, data)
+	_, err = c.Ctx.ResponseWriter.Write([]byte(message))
+	return err
+}
+
+// GetEvents pushes the events of the member by server-sent events: new notifications, the unread notification
+// number, and the new replies of the topic if topicId is given. Reconnecting clients get the unread notifications
+// after the Last-Event-ID header or the lastId param first. A comment is sent as the heartbeat.
+func (c *APIController) GetEvents() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	if object.IsForbidden(memberId) {
+		c.forbiddenAccountResp(memberId)
+		return
+	}
+
+	topicId := 0
+	if topicIdStr := c.Input().Get("topicId"); topicIdStr != "" {
+		var topicInfo *object.Topic
+		if id, err := strconv.Atoi(topicIdStr); err == nil {
+			topicId = id
+			topicInfo = object.GetTopicBasicInfo(topicId)
+		}
+		if topicInfo == nil || topicInfo.Deleted {
+			resp := Response{Status: "fail", Msg: "Topic doesn't exist."}
+			c.Data["json"] = resp
+			c.ServeJSON()
+			return
+		}
+	}
+	lastIdStr := c.Ctx.Input.Header("Last-Event-ID")
+	if lastIdStr == "" {
+		lastIdStr = c.Input().Get("lastId")
+	}
+	// a bad id is ignored, the response can't be changed to an error after the header is written
+	lastId, err := strconv.Atoi(lastIdStr)
+	if err != nil {
+		lastId = -1
+	}
+
+	// subscribe before catching up, so that no notification is missed in between
+	subscriber := object.SubscribeEvents(memberId, topicId)
+	defer object.UnsubscribeEvents(subscriber)
+
+	writer := c.Ctx.ResponseWriter
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.Header().Set("Connection", "keep-alive")
+	writer.Header().Set("X-Accel-Buffering", "no")
+	writer.WriteHeader(http.StatusOK)
+
+	if lastId >= 0 {
+		for _, notification := range object.GetUnreadNotificationsAfter(memberId, lastId) {
+			_ = c.writeEvent(&object.Event{Id: notification.Id, Type: object.EventNotification, Data: notification})
+			lastId = notification.Id
+		}
+	}
+	_ = c.writeEvent(&object.Event{Type: object.EventUnreadNum, Data: object.GetUnreadNotificationNum(memberId)})
+	writer.Flush()
+
+	ticker := time.NewTicker(time.Second * time.Duration(object.EventHeartbeatInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Ctx.Request.Context().Done():
+			return
+		case event := <-subscriber.Events:
+			// the notification may have been sent when catching up
+			if event.Type == object.EventNotification && event.Id <= lastId {
+				continue
+			}
+			if event.SenderId != "" && object.IsMemberBlocked(memberId, event.SenderId) {
+				continue
+			}
+			err = c.writeEvent(event)
+		case <-ticker.C:
+			// the session may have been revoked or the member forbidden since the stream started
+			if object.IsForbidden(memberId) || !object.TouchSessionRecord(c.CruSession.SessionID(), memberId, util.GetClientIp(c.Ctx.Request)) {
+				return
+			}
+			_, err = writer.Write([]byte(": heartbeat\n\n"))
+		}
+		if err != nil {
+			return
+		}
+		writer.Flush()
+	}
+}
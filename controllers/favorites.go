@@ -49,20 +49,10 @@ func (c *APIController) AddFavorites() {
 	var resp Response
 	if favoritesType <= 3 && favoritesType >= 1 {
 		res := object.AddFavorites(&favorites)
-		if favoritesType == 1 {
-			topicId := util.ParseInt(objectId)
-			notification := object.Notification{
-				//Id:               util.IntToString(object.GetNotificationId()),
-				NotificationType: 4,
-				ObjectId:         topicId,
-				CreatedTime:      util.GetCurrentTime(),
-				SenderId:         c.GetSessionUser(),
-				ReceiverId:       object.GetTopicAuthor(topicId),
-				Status:           1,
-			}
-			if notification.ReceiverId != notification.SenderId {
-				_ = object.AddNotification(&notification)
-			}
+		if res && favoritesType == 1 {
+			object.AddFavoriteNotification(memberId, util.ParseInt(objectId))
+		} else if res && favoritesType == 2 {
+			object.AddFollowNotification(memberId, objectId)
 		}
 		resp = Response{Status: "ok", Msg: "success", Data: res}
 	} else {
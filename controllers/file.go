@@ -17,9 +17,7 @@ package controllers
 import (
 	"encoding/base64"
 	"encoding/json"
-	"strconv"
 	"strings"
-	"time"
 
 	"github.com/casbin/casnode/object"
 	"github.com/casbin/casnode/service"
@@ -85,6 +83,9 @@ func (c *APIController) AddFileRecord() {
 
 	var resp Response
 	memberId := c.GetSessionUser()
+	if c.RequireTrustLevel(memberId, object.UploadFileTrustLevel, "upload files") {
+		return
+	}
 
 	uploadFileNum := object.GetFilesNum(memberId)
 	if uploadFileNum >= object.GetMemberFileQuota(memberId) {
@@ -194,6 +195,9 @@ func (c *APIController) UploadFile() {
 		return
 	}
 	memberId := c.GetSessionUser()
+	if c.RequireTrustLevel(memberId, object.UploadFileTrustLevel, "upload files") {
+		return
+	}
 	fileBase64 := c.Ctx.Request.Form.Get("file")
 	fileType := c.Ctx.Request.Form.Get("type")
 	fileName := c.Ctx.Request.Form.Get("name")
@@ -219,10 +223,22 @@ func (c *APIController) UploadAvatar() {
 		c.ServeJSON()
 		return
 	}
-	fileBytes, _ := base64.StdEncoding.DecodeString(avatarBase64[index+1:])
-	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
-	fileURL := service.UploadFileToOSS(fileBytes, "/" + memberId + "/avatar/" + timestamp + "." + "png")
-	resp := Response{Status: "ok", Data: fileURL}
+	fileBytes, err := base64.StdEncoding.DecodeString(avatarBase64[index+1:])
+	if err != nil || len(fileBytes) > object.MaxAvatarFileSize<<20 {
+		resp := Response{Status: "error", Msg: "File encoding or size error"}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+	images, err := util.ProcessAvatar(fileBytes, object.AvatarSizes)
+	if err != nil {
+		resp := Response{Status: "error", Msg: err.Error()}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+	fileURL, urls := saveAvatar(images, memberId)
+	resp := Response{Status: "ok", Data: fileURL, Data2: urls}
 	c.Data["json"] = resp
 	c.ServeJSON()
 }
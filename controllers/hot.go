@@ -20,6 +20,12 @@ import (
 )
 
 func (c *APIController) ChangeExpiredDataStatus() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	expiredNodeDate := util.GetTimeMonth(-object.NodeHitRecordExpiredTime)
 	expiredTopicDate := util.GetTimeDay(-object.TopicHitRecordExpiredTime)
 
@@ -31,6 +37,12 @@ func (c *APIController) ChangeExpiredDataStatus() {
 }
 
 func (c *APIController) UpdateHotInfo() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	var updateNodeNum int
 	var updateTopicNum int
 	last := object.GetLastRecordId()
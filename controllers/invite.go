@@ -0,0 +1,128 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/util"
+)
+
+func (c *APIController) GetSignupMode() {
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetSignupMode()}
+	c.ServeJSON()
+}
+
+func (c *APIController) UpdateSignupMode() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	mode := c.Input().Get("mode")
+	if !object.IsValidSignupMode(mode) {
+		resp := Response{Status: "fail", Msg: "Unknown signup mode: " + mode}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	util.LogInfo(c.Ctx, "API: [%s] changed the signup mode to %s", memberId, mode)
+	c.wrapResponse(object.UpdateSignupMode(mode))
+}
+
+func (c *APIController) GetInviteCodes() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetMemberInviteCodes(memberId)}
+	c.ServeJSON()
+}
+
+// AddInviteCode creates an invite code, which costs InviteCodeCost coins for members other than admin.
+func (c *APIController) AddInviteCode() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	if object.IsMuted(memberId, "") || object.IsForbidden(memberId) {
+		c.mutedAccountResp(memberId)
+		return
+	}
+	if object.IsPendingApproval(memberId) {
+		c.pendingAccountResp(memberId)
+		return
+	}
+
+	cost := object.InviteCodeCost
+	if c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		cost = 0
+	}
+
+	inviteCode, msg := object.AddInviteCode(memberId, cost)
+	if inviteCode == nil {
+		resp := Response{Status: "fail", Msg: msg}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: inviteCode}
+	c.ServeJSON()
+}
+
+func (c *APIController) DeleteInviteCode() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	id := util.ParseInt(c.Input().Get("id"))
+
+	c.wrapResponse(object.DeleteInviteCode(memberId, id))
+}
+
+// GetPendingMembers returns the members waiting for approval in the approval mode.
+func (c *APIController) GetPendingMembers() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetPendingMembers()}
+	c.ServeJSON()
+}
+
+// HandlePendingMember approves the member, or rejects it with approved=false.
+func (c *APIController) HandlePendingMember() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	id := c.Input().Get("id")
+	approved := c.Input().Get("approved") == "true"
+	res := object.HandlePendingMember(id, approved)
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] handled pending member [%s], approved: %t", memberId, id, approved)
+	}
+
+	c.wrapResponse(res)
+}
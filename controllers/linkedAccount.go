@@ -0,0 +1,82 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/util"
+)
+
+// GetLinkedAccounts returns the identities linked with the member, and whether the member has a password in Data2.
+func (c *APIController) GetLinkedAccounts() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	hasPassword := !object.IsPasswordCorrect(memberId, "")
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetMemberLinkedAccounts(memberId), Data2: hasPassword}
+	c.ServeJSON()
+}
+
+// UnlinkAccount unlinks the identity of the provider. The member needs to enter the password, or sign in again
+// if there is no password, and the two-factor code if it's enabled.
+func (c *APIController) UnlinkAccount() {
+	if c.RequireLogin() {
+		return
+	}
+
+	var form unlinkAccountForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	var resp Response
+	memberId := c.GetSessionUser()
+	if object.IsPasswordCorrect(memberId, "") {
+		if !c.recentlyAuthenticated() {
+			resp = Response{Status: "fail", Msg: "errorReauthRequired"}
+			c.Data["json"] = resp
+			c.ServeJSON()
+			return
+		}
+	} else if form.Password == "" || !object.IsPasswordCorrect(memberId, form.Password) {
+		resp = Response{Status: "fail", Msg: "Password error"}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+	if object.GetMemberTotpEnabled(memberId) && !object.VerifyMemberTwoFactor(memberId, form.Code, form.RecoveryCode) {
+		resp = Response{Status: "fail", Msg: "Two-factor code error"}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	res, msg := object.UnlinkAccount(memberId, form.Provider)
+	if !res {
+		resp = Response{Status: "fail", Msg: msg}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	util.LogInfo(c.Ctx, "API: [%s] unlinked the %s account", memberId, form.Provider)
+	c.wrapResponse(res)
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/util"
+)
+
+// GetQueuedMails returns a page of the outbound mails of the state: pending, sent, dead, or all if it's empty.
+func (c *APIController) GetQueuedMails() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	state := c.Input().Get("state")
+	limit, offset := c.getPage()
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetQueuedMails(state, limit, offset), Data2: object.GetQueuedMailNum(state)}
+	c.ServeJSON()
+}
+
+// RetryQueuedMail puts the dead mail back to the queue.
+func (c *APIController) RetryQueuedMail() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	id := util.ParseInt(c.Input().Get("id"))
+	mail := object.GetQueuedMail(id)
+	if mail == nil || mail.State != object.MailStateDead {
+		c.Data["json"] = Response{Status: "fail", Msg: "Only dead mails can be retried."}
+		c.ServeJSON()
+		return
+	}
+
+	res := object.RetryQueuedMail(id)
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] retried mail %d", memberId, id)
+	}
+
+	c.wrapResponse(res)
+}
+
+func (c *APIController) DeleteQueuedMail() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	id := util.ParseInt(c.Input().Get("id"))
+	res := object.DeleteQueuedMail(id)
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] deleted mail %d", memberId, id)
+	}
+
+	c.wrapResponse(res)
+}
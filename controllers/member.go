@@ -21,12 +21,41 @@ import (
 	"github.com/casbin/casnode/util"
 )
 
+// GetMembers returns a page of the member directory, q searches the names and taglines, sort is "joined" or "active".
 func (c *APIController) GetMembers() {
-	c.Data["json"] = object.GetMembers()
+	limitStr := c.Input().Get("limit")
+	pageStr := c.Input().Get("page")
+	keyword := c.Input().Get("q")
+	sort := c.Input().Get("sort")
+
+	limit := object.DefaultPageNum
+	if len(limitStr) != 0 {
+		limit = util.ParseInt(limitStr)
+		if limit <= 0 || limit > object.DefaultMemberAdminPageNum {
+			limit = object.DefaultPageNum
+		}
+	}
+	offset := 0
+	if len(pageStr) != 0 {
+		page := util.ParseInt(pageStr)
+		if page > 1 {
+			offset = page*limit - limit
+		}
+	}
+
+	res, num := object.GetMemberDirectory(keyword, sort, limit, offset)
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: res, Data2: num}
 	c.ServeJSON()
 }
 
 func (c *APIController) GetMembersAdmin() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	limitStr := c.Input().Get("limit")
 	pageStr := c.Input().Get("page")
 	un := c.Input().Get("un") // search: username
@@ -52,6 +81,12 @@ func (c *APIController) GetMembersAdmin() {
 }
 
 func (c *APIController) GetMemberAdmin() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	id := c.Input().Get("id")
 
 	c.Data["json"] = object.GetMemberAdmin(id)
@@ -61,7 +96,21 @@ func (c *APIController) GetMemberAdmin() {
 func (c *APIController) GetMember() {
 	id := c.Input().Get("id")
 
-	c.Data["json"] = object.GetMember(id)
+	// the profile urls of the old names lead to the renamed member
+	member := object.GetMember(object.ResolveMemberId(id))
+	if member == nil {
+		c.Data["json"] = nil
+		c.ServeJSON()
+		return
+	}
+
+	// members see all of their own fields, the others only see the public ones
+	if member.Id == c.GetSessionUser() {
+		member.LinkedAccounts = object.GetMemberLinkedAccounts(member.Id)
+		c.Data["json"] = member
+	} else {
+		c.Data["json"] = object.GetPublicMember(member)
+	}
 	c.ServeJSON()
 }
 
@@ -88,6 +137,32 @@ func (c *APIController) UpdateMemberEmailReminder() {
 	c.ServeJSON()
 }
 
+// UpdateMemberDigestFrequency turns on the "daily" or "weekly" digest email, or turns it off with the empty frequency.
+func (c *APIController) UpdateMemberDigestFrequency() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	frequency := c.Input().Get("frequency")
+	if _, ok := object.DigestIntervals[frequency]; !ok && frequency != "" {
+		c.Data["json"] = Response{Status: "fail", Msg: "Invalid digest frequency."}
+		c.ServeJSON()
+		return
+	}
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.UpdateMemberDigestFrequency(memberId, frequency)}
+	c.ServeJSON()
+}
+
+func (c *APIController) UpdateMemberAutoWatchReply() {
+	memberId := c.GetSessionUser()
+	status := c.Input().Get("status")
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.ChangeMemberAutoWatchReply(memberId, status)}
+	c.ServeJSON()
+}
+
 func (c *APIController) UpdateMember() {
 	id := c.Input().Get("id")
 
@@ -95,7 +170,7 @@ func (c *APIController) UpdateMember() {
 	var memberInfo object.AdminMemberInfo
 	var resp Response
 
-	if !object.CheckModIdentity(c.GetSessionUser()) {
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
 		resp = Response{Status: "fail", Msg: "Unauthorized."}
 		c.Data["json"] = resp
 		c.ServeJSON()
@@ -110,7 +185,27 @@ func (c *APIController) UpdateMember() {
 	member.FileQuota = memberInfo.FileQuota
 	member.Status = memberInfo.Status
 
-	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.UpdateMember(id, &member)}
+	status := object.GetMemberStatus(id)
+	res := object.UpdateMember(id, &member)
+
+	// muting and forbidding here are permanent site suspensions, the timed ones are added by SuspendMember()
+	if res && memberInfo.Status != status {
+		object.LiftMemberSuspensions(id, c.GetSessionUser())
+		if memberInfo.Status == 2 || memberInfo.Status == 3 {
+			suspension := object.Suspension{
+				MemberId:    id,
+				Type:        object.SuspensionMute,
+				Actor:       c.GetSessionUser(),
+				CreatedTime: util.GetCurrentTime(),
+			}
+			if memberInfo.Status == 3 {
+				suspension.Type = object.SuspensionForbid
+			}
+			object.AddSuspension(&suspension)
+		}
+	}
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: res}
 	c.ServeJSON()
 }
 
@@ -135,6 +230,12 @@ func (c *APIController) UpdateMemberInfo() {
 			Website:      tempMember.Website,
 			Tagline:      tempMember.Tagline,
 			Location:     tempMember.Location,
+
+			EmailPublic:     tempMember.EmailPublic,
+			PhonePublic:     tempMember.PhonePublic,
+			AccountsPublic:  tempMember.AccountsPublic,
+			ThanksPublic:    tempMember.ThanksPublic,
+			FavoritesPublic: tempMember.FavoritesPublic,
 		}
 		res := object.UpdateMemberInfo(id, &member)
 		resp = Response{Status: "ok", Msg: "success", Data: res}
@@ -224,6 +325,12 @@ func (c *APIController) GetMemberLanguage() {
 }
 
 func (c *APIController) AddMember() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	var member object.Member
 	err := json.Unmarshal(c.Ctx.Input.RequestBody, &member)
 	if err != nil {
@@ -235,6 +342,12 @@ func (c *APIController) AddMember() {
 }
 
 func (c *APIController) DeleteMember() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	id := c.Input().Get("id")
 
 	c.Data["json"] = object.DeleteMember(id)
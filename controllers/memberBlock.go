@@ -0,0 +1,72 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/util"
+)
+
+func (c *APIController) GetMemberBlocks() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetMemberBlocks(memberId)}
+	c.ServeJSON()
+}
+
+func (c *APIController) GetMemberBlockStatus() {
+	memberId := c.GetSessionUser()
+	id := c.Input().Get("id")
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.IsMemberBlocked(memberId, id)}
+	c.ServeJSON()
+}
+
+func (c *APIController) AddMemberBlock() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	id := c.Input().Get("id")
+	if id == memberId || !object.HasMember(id) {
+		resp := Response{Status: "fail", Msg: "You can't block this member."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	block := object.MemberBlock{
+		MemberId:    memberId,
+		BlockedId:   id,
+		CreatedTime: util.GetCurrentTime(),
+	}
+
+	c.wrapResponse(object.AddMemberBlock(&block))
+}
+
+func (c *APIController) DeleteMemberBlock() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	id := c.Input().Get("id")
+
+	c.wrapResponse(object.DeleteMemberBlock(memberId, id))
+}
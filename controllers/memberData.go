@@ -0,0 +1,87 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/service"
+	"github.com/casbin/casnode/util"
+)
+
+// ExportAccount downloads all the data of the member as a json file.
+func (c *APIController) ExportAccount() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	export := object.GetMemberExport(memberId)
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	util.LogInfo(c.Ctx, "API: [%s] exported the account data", memberId)
+	c.Ctx.Output.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.json", memberId, util.GetDateStr()))
+	c.Ctx.Output.Header("Content-Type", "application/json; charset=utf-8")
+	err = c.Ctx.Output.Body(data)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// DeleteAccount deletes the member after checking the password, and the two-factor code if it's enabled.
+func (c *APIController) DeleteAccount() {
+	if c.RequireLogin() {
+		return
+	}
+
+	var form deleteAccountForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	memberId := c.GetSessionUser()
+	if form.Password == "" || !object.IsPasswordCorrect(memberId, form.Password) {
+		resp := Response{Status: "fail", Msg: "Password error"}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+	if object.GetMemberTotpEnabled(memberId) && !object.VerifyMemberTwoFactor(memberId, form.Code, form.RecoveryCode) {
+		resp := Response{Status: "fail", Msg: "Two-factor code error"}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	res, paths := object.DeleteMemberAccount(memberId)
+	if res {
+		for _, path := range paths {
+			service.DeleteOSSFile(path)
+		}
+		// the avatars aren't file records, they are all under the avatar directory of the member
+		service.DeleteOSSDir("/" + memberId + "/avatar/")
+		c.SetSession("username", "")
+		util.LogInfo(c.Ctx, "API: [%s] deleted the account", memberId)
+	}
+
+	c.wrapResponse(res)
+}
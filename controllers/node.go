@@ -27,6 +27,12 @@ func (c *APIController) GetNodes() {
 }
 
 func (c *APIController) GetNodesAdmin() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	res := []adminNodeInfo{}
 	nodes := object.GetNodes()
 	for _, v := range nodes {
@@ -49,13 +55,21 @@ func (c *APIController) GetNode() {
 	c.ServeJSON()
 }
 
+// GetNodePolicy returns the effective posting policy of the node, with the global defaults filled in.
+func (c *APIController) GetNodePolicy() {
+	id := c.Input().Get("id")
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetEffectiveNodePolicy(id)}
+	c.ServeJSON()
+}
+
 func (c *APIController) UpdateNode() {
 	id := c.Input().Get("id")
 
 	var resp Response
 	var node object.Node
 
-	if !object.CheckModIdentity(c.GetSessionUser()) {
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
 		c.RequireAdmin(c.GetSessionUser())
 		return
 	}
@@ -75,7 +89,7 @@ func (c *APIController) AddNode() {
 	var node object.Node
 	var resp Response
 
-	if !object.CheckModIdentity(c.GetSessionUser()) {
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
 		c.RequireAdmin(c.GetSessionUser())
 		return
 	}
@@ -110,7 +124,7 @@ func (c *APIController) AddNode() {
 func (c *APIController) DeleteNode() {
 	id := c.Input().Get("id")
 
-	if !object.CheckModIdentity(c.GetSessionUser()) {
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
 		c.RequireAdmin(c.GetSessionUser())
 		return
 	}
@@ -227,8 +241,7 @@ func (c *APIController) AddNodeModerators() {
 	var moderators addNodeModerator
 	var resp Response
 
-	memberId := c.GetSessionUser()
-	if !object.CheckModIdentity(memberId) {
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
 		resp = Response{Status: "fail", Msg: "Unauthorized."}
 		c.Data["json"] = resp
 		c.ServeJSON()
@@ -247,6 +260,12 @@ func (c *APIController) AddNodeModerators() {
 		c.ServeJSON()
 		return
 	}
+	if !object.HasNode(moderators.NodeId) {
+		resp = Response{Status: "fail", Msg: "Node doesn't exist."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
 
 	res := object.AddNodeModerators(moderators.MemberId, moderators.NodeId)
 	if res {
@@ -263,8 +282,7 @@ func (c *APIController) DeleteNodeModerators() {
 	var moderators deleteNodeModerator
 	var resp Response
 
-	memberId := c.GetSessionUser()
-	if !object.CheckModIdentity(memberId) {
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
 		resp = Response{Status: "fail", Msg: "Unauthorized."}
 		c.Data["json"] = resp
 		c.ServeJSON()
@@ -22,13 +22,18 @@ import (
 )
 
 func (c *APIController) AddNotification() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	var tempNotification newNotification
 	err := json.Unmarshal(c.Ctx.Input.RequestBody, &tempNotification)
 	if err != nil {
 		panic(err)
 	}
 
-	memberId := c.GetSessionUser()
 	notification := object.Notification{
 		//Id:               util.IntToString(object.GetNotificationId()),
 		NotificationType: tempNotification.NotificationType,
@@ -82,10 +87,15 @@ func (c *APIController) GetNotifications() {
 }
 
 func (c *APIController) DeleteNotification() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
 	id := c.Input().Get("id")
 
 	var resp Response
-	res := object.DeleteNotification(id)
+	res := object.DeleteNotification(memberId, id)
 	resp = Response{Status: "ok", Msg: "success", Data: res}
 
 	c.Data["json"] = resp
@@ -109,3 +119,38 @@ func (c *APIController) UpdateReadStatus() {
 	c.Data["json"] = object.UpdateReadStatus(memberId)
 	c.ServeJSON()
 }
+
+func (c *APIController) GetNotificationPreferences() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetNotificationPreferences(memberId)}
+	c.ServeJSON()
+}
+
+// UpdateNotificationPreferences saves the channels of the notification categories, the categories not given are kept.
+func (c *APIController) UpdateNotificationPreferences() {
+	if c.RequireLogin() {
+		return
+	}
+
+	var preferences []*object.NotificationPreference
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &preferences)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, preference := range preferences {
+		if !object.IsValidNotificationCategory(preference.Category) {
+			resp := Response{Status: "fail", Msg: "Invalid notification category."}
+			c.Data["json"] = resp
+			c.ServeJSON()
+			return
+		}
+	}
+
+	memberId := c.GetSessionUser()
+	c.wrapResponse(object.UpdateNotificationPreferences(memberId, preferences))
+}
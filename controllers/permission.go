@@ -0,0 +1,124 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/util"
+)
+
+// CheckPermission returns whether the current member has the permission in the domain.
+// The elevated permissions also need an access token with the moderate scope.
+func (c *APIController) CheckPermission(domain, permission string) bool {
+	if object.IsElevatedPermission(permission) && !c.tokenAllowsModeration() {
+		return false
+	}
+
+	return object.CheckPermission(c.GetSessionUser(), domain, permission)
+}
+
+// checkAuthorOrPermission returns whether the current member is the author, or has the permission in the domain.
+func (c *APIController) checkAuthorOrPermission(author, domain, permission string) bool {
+	memberId := c.GetSessionUser()
+	if memberId != "" && memberId == author {
+		return true
+	}
+
+	return c.CheckPermission(domain, permission)
+}
+
+// GetMemberRoles returns the roles of the member, or the members with the role if the role is given.
+func (c *APIController) GetMemberRoles() {
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(c.GetSessionUser())
+		return
+	}
+
+	var roles []*object.MemberRole
+	if role := c.Input().Get("role"); role != "" {
+		roles = object.GetRoleMembers(role, c.Input().Get("domain"))
+	} else {
+		roles = object.GetMemberRoles(c.Input().Get("id"))
+	}
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: roles}
+	c.ServeJSON()
+}
+
+func (c *APIController) AddMemberRole() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	var form memberRoleForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	var resp Response
+	if !object.HasMember(form.MemberId) {
+		resp = Response{Status: "fail", Msg: "Member doesn't exist."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+	if !object.IsValidMemberRole(form.Role, form.Domain) {
+		resp = Response{Status: "fail", Msg: "The role can't be granted in this domain."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	res := object.AddMemberRole(form.MemberId, form.Role, form.Domain)
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] granted role %s in %s to [%s]", memberId, form.Role, form.Domain, form.MemberId)
+	}
+
+	c.wrapResponse(res)
+}
+
+func (c *APIController) DeleteMemberRole() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	var form memberRoleForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	// the site would be left without anyone to manage it
+	if form.MemberId == memberId && form.Role == object.RoleAdmin {
+		resp := Response{Status: "fail", Msg: "You can't remove your own admin role."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	res := object.DeleteMemberRole(form.MemberId, form.Role, form.Domain)
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] revoked role %s in %s from [%s]", memberId, form.Role, form.Domain, form.MemberId)
+	}
+
+	c.wrapResponse(res)
+}
@@ -27,6 +27,12 @@ func (c *APIController) GetPlanes() {
 }
 
 func (c *APIController) GetPlanesAdmin() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	c.Data["json"] = object.GetAllPlanes()
 	c.ServeJSON()
 }
@@ -39,6 +45,12 @@ func (c *APIController) GetPlane() {
 }
 
 func (c *APIController) GetPlaneAdmin() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	id := c.Input().Get("id")
 
 	c.Data["json"] = object.GetPlaneAdmin(id)
@@ -58,7 +70,7 @@ func (c *APIController) AddPlane() {
 	var plane object.AdminPlaneInfo
 	var resp Response
 
-	if !object.CheckModIdentity(c.GetSessionUser()) {
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
 		c.RequireAdmin(c.GetSessionUser())
 		return
 	}
@@ -105,7 +117,7 @@ func (c *APIController) UpdatePlane() {
 	var resp Response
 	var plane object.AdminPlaneInfo
 
-	if !object.CheckModIdentity(c.GetSessionUser()) {
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
 		c.RequireAdmin(c.GetSessionUser())
 		return
 	}
@@ -134,7 +146,7 @@ func (c *APIController) UpdatePlane() {
 func (c *APIController) DeletePlane() {
 	id := c.Input().Get("id")
 
-	if !object.CheckModIdentity(c.GetSessionUser()) {
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
 		c.RequireAdmin(c.GetSessionUser())
 		return
 	}
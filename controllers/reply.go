@@ -23,8 +23,9 @@ import (
 )
 
 type NewReplyForm struct {
-	Content string `json:"content"`
-	TopicId int    `json:"topicId"`
+	Content    string `json:"content"`
+	TopicId    int    `json:"topicId"`
+	EditorType string `json:"editorType"`
 }
 
 func (c *APIController) GetReplies() {
@@ -86,6 +87,12 @@ func (c *APIController) GetReplyWithDetails() {
 }
 
 func (c *APIController) UpdateReply() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	idStr := c.Input().Get("id")
 
 	var reply object.Reply
@@ -106,10 +113,14 @@ func (c *APIController) AddReply() {
 
 	memberId := c.GetSessionUser()
 	// check account status
-	if object.IsMuted(memberId) || object.IsForbidden(memberId) {
+	if object.IsMuted(memberId, "") || object.IsForbidden(memberId) {
 		c.mutedAccountResp(memberId)
 		return
 	}
+	if object.IsPendingApproval(memberId) {
+		c.pendingAccountResp(memberId)
+		return
+	}
 
 	var form NewReplyForm
 	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
@@ -125,6 +136,13 @@ func (c *APIController) AddReply() {
 		return
 	}
 
+	if msg := object.CheckTrustLevelContent(memberId, content); msg != "" {
+		resp := Response{Status: "fail", Msg: msg}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
 	reply := object.Reply{
 		//Id:          util.IntToString(object.GetReplyId()),
 		Author:      memberId,
@@ -132,15 +150,40 @@ func (c *APIController) AddReply() {
 		CreatedTime: util.GetCurrentTime(),
 		Content:     content,
 		Deleted:     false,
+		EditorType:  form.EditorType,
 	}
 
-	err = json.Unmarshal(c.Ctx.Input.RequestBody, &reply)
-	if err != nil {
-		panic(err)
+	topicInfo := object.GetTopicBasicInfo(topicId)
+	if topicInfo == nil || topicInfo.Deleted {
+		resp := Response{Status: "fail", Msg: "Topic doesn't exist."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	nodeId := topicInfo.NodeId
+	if object.IsMuted(memberId, nodeId) {
+		c.nodeMutedAccountResp(memberId)
+		return
+	}
+
+	policy := object.GetEffectiveNodePolicy(nodeId)
+	if !policy.EditorTypeAllowed(reply.EditorType) {
+		resp := Response{Status: "fail", Msg: "This editor type is not allowed in this node."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	if !policy.AccountOldEnough(memberId) && !c.CheckPermission(object.GetNodeDomain(nodeId), object.PermissionPostRestricted) {
+		resp := Response{Status: "fail", Msg: "Your account is too new to post in this node."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
 	}
 
 	balance := object.GetMemberBalance(memberId)
-	if balance < object.CreateReplyCost {
+	if balance < policy.CreateReplyCost {
 		resp := Response{Status: "fail", Msg: "You don't have enough balance."}
 		c.Data["json"] = resp
 		c.ServeJSON()
@@ -150,9 +193,12 @@ func (c *APIController) AddReply() {
 	affected, id := object.AddReply(&reply)
 	if affected {
 		object.GetReplyBonus(object.GetTopicAuthor(reply.TopicId), reply.Author, id)
-		object.CreateReplyConsumption(reply.Author, id)
+		object.CreateReplyConsumption(reply.Author, id, policy.CreateReplyCost)
 		object.ChangeTopicReplyCount(topicId, 1)
 		object.ChangeTopicLastReplyUser(topicId, memberId, util.GetCurrentTime())
+		if object.GetMemberAutoWatchReply(memberId) {
+			object.AutoWatchTopic(memberId, topicId)
+		}
 		object.AddReplyNotification(reply.Author, reply.Content, id, reply.TopicId)
 	}
 
@@ -165,8 +211,10 @@ func (c *APIController) DeleteReply() {
 	memberId := c.GetSessionUser()
 	id := util.ParseInt(idStr)
 	replyInfo := object.GetReply(id)
-	isModerator := object.CheckModIdentity(memberId)
-	if !object.ReplyDeletable(replyInfo.CreatedTime, memberId, replyInfo.Author) && !isModerator {
+	nodeId := object.GetTopicNodeId(replyInfo.TopicId)
+	isModerator := c.CheckPermission(object.GetNodeDomain(nodeId), object.PermissionModerate)
+	policy := object.GetEffectiveNodePolicy(nodeId)
+	if !object.ReplyDeletable(replyInfo.CreatedTime, memberId, replyInfo.Author, policy.ReplyDeletableTime) && !isModerator {
 		resp := Response{Status: "fail", Msg: "Permission denied."}
 		c.Data["json"] = resp
 		c.ServeJSON()
@@ -223,3 +271,59 @@ func (c *APIController) GetMemberRepliesNum() {
 	c.Data["json"] = object.GetMemberRepliesNum(id)
 	c.ServeJSON()
 }
+
+// AcceptReply marks the reply as the answer of its topic, by the topic author or a moderator of the node.
+func (c *APIController) AcceptReply() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	id := util.ParseInt(c.Input().Get("id"))
+	reply := object.GetReply(id)
+	if reply == nil || reply.Deleted {
+		resp := Response{Status: "fail", Msg: "Reply doesn't exist."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	topic := object.GetTopicBasicInfo(reply.TopicId)
+	if topic == nil || !c.checkAuthorOrPermission(topic.Author, object.GetNodeDomain(topic.NodeId), object.PermissionModerate) {
+		resp := Response{Status: "fail", Msg: "Permission denied."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+	if reply.Author == topic.Author {
+		resp := Response{Status: "fail", Msg: "The reply of the topic author can't be accepted."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	res := object.UpdateTopicAcceptedReply(topic.Id, id)
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] accepted reply %d of topic %d", memberId, id, topic.Id)
+	}
+
+	c.wrapResponse(res)
+}
+
+// UnacceptReply clears the accepted answer of the topic.
+func (c *APIController) UnacceptReply() {
+	if c.RequireLogin() {
+		return
+	}
+
+	id := util.ParseInt(c.Input().Get("id"))
+	topic := object.GetTopicBasicInfo(id)
+	if topic == nil || !c.checkAuthorOrPermission(topic.Author, object.GetNodeDomain(topic.NodeId), object.PermissionModerate) {
+		resp := Response{Status: "fail", Msg: "Permission denied."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	c.wrapResponse(object.UpdateTopicAcceptedReply(id, 0))
+}
@@ -23,9 +23,7 @@ func (c *APIController) AddSensitive() {
 	if c.RequireLogin() {
 		return
 	}
-	memberId := c.GetSessionUser()
-	member := object.GetMember(memberId)
-	if !member.IsModerator {
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
 		resp := Response{Status: "fail", Msg: "You are not admin, you can't add sensitive words."}
 		c.Data["json"] = resp
 		c.ServeJSON()
@@ -61,9 +59,7 @@ func (c *APIController) DelSensitive() {
 	if c.RequireLogin() {
 		return
 	}
-	memberId := c.GetSessionUser()
-	member := object.GetMember(memberId)
-	if !member.IsModerator {
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
 		resp := Response{Status: "fail", Msg: "You are not admin, you can't delete sensitive words."}
 		c.Data["json"] = resp
 		c.ServeJSON()
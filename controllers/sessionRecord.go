@@ -0,0 +1,75 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/util"
+)
+
+// GetSessions returns the signed in sessions of the member, the one of this request is marked as current.
+func (c *APIController) GetSessions() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	currentId := object.GetSessionRecordId(c.CruSession.SessionID())
+	records := object.GetMemberSessionRecords(memberId)
+	for _, record := range records {
+		record.Current = record.Id == currentId
+	}
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: records}
+	c.ServeJSON()
+}
+
+func (c *APIController) DeleteSession() {
+	if c.RequireLogin() {
+		return
+	}
+
+	id := c.Input().Get("id")
+	memberId := c.GetSessionUser()
+	if id == object.GetSessionRecordId(c.CruSession.SessionID()) {
+		resp := Response{Status: "fail", Msg: "Please sign out to end the current session."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	res := object.RevokeSession(memberId, id)
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] revoked a session", memberId)
+	}
+
+	c.wrapResponse(res)
+}
+
+// SignoutEverywhere signs out all the sessions of the member, including the current one.
+func (c *APIController) SignoutEverywhere() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	num := object.RevokeMemberSessions(memberId, "")
+	c.SetSessionUser("")
+
+	util.LogInfo(c.Ctx, "API: [%s] signed out %d sessions", memberId, num)
+	resp := Response{Status: "ok", Msg: "success", Data: num}
+	c.Data["json"] = resp
+	c.ServeJSON()
+}
@@ -0,0 +1,151 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/util"
+)
+
+// getSuspensionDomain returns the permission domain of the suspension scope.
+func getSuspensionDomain(nodeId string) string {
+	if nodeId == "" {
+		return object.DomainSite
+	}
+	return object.GetNodeDomain(nodeId)
+}
+
+// SuspendMember mutes the member in the site or a node, or forbids the member from signing in,
+// for Duration hours or permanently if it's 0. Node moderators can only mute in their nodes.
+func (c *APIController) SuspendMember() {
+	if c.RequireLogin() {
+		return
+	}
+
+	var form suspensionForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	var resp Response
+	memberId := c.GetSessionUser()
+	domain := getSuspensionDomain(form.NodeId)
+	if !c.CheckPermission(domain, object.PermissionModerate) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	if form.Type != object.SuspensionMute && form.Type != object.SuspensionForbid {
+		resp = Response{Status: "fail", Msg: "Invalid suspension type."}
+	} else if form.Type == object.SuspensionForbid && form.NodeId != "" {
+		resp = Response{Status: "fail", Msg: "Members can only be forbidden in the whole site."}
+	} else if form.NodeId != "" && !object.HasNode(form.NodeId) {
+		resp = Response{Status: "fail", Msg: "Node doesn't exist."}
+	} else if form.Duration < 0 {
+		resp = Response{Status: "fail", Msg: "Invalid duration."}
+	} else if !object.HasMember(form.MemberId) || form.MemberId == memberId || object.CheckPermission(form.MemberId, domain, object.PermissionModerate) {
+		resp = Response{Status: "fail", Msg: "You can't suspend this member."}
+	}
+	if resp.Status != "" {
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	suspension := object.Suspension{
+		MemberId:    form.MemberId,
+		Type:        form.Type,
+		NodeId:      form.NodeId,
+		Reason:      form.Reason,
+		Actor:       memberId,
+		CreatedTime: util.GetCurrentTime(),
+	}
+	if form.Duration > 0 {
+		suspension.EndTime = util.GetTimeHour(form.Duration)
+	}
+
+	res := object.AddSuspension(&suspension)
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] suspended [%s], type: %s, node: %s, duration: %d hours", memberId, form.MemberId, form.Type, form.NodeId, form.Duration)
+	}
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: res, Data2: suspension.Id}
+	c.ServeJSON()
+}
+
+// LiftSuspension lifts the active suspension before it ends.
+func (c *APIController) LiftSuspension() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	id := util.ParseInt(c.Input().Get("id"))
+	suspension := object.GetSuspension(id)
+	if suspension == nil || !c.CheckPermission(getSuspensionDomain(suspension.NodeId), object.PermissionModerate) {
+		c.RequireAdmin(memberId)
+		return
+	}
+	if !suspension.IsActive() {
+		resp := Response{Status: "fail", Msg: "The suspension is not active."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	res := object.LiftSuspension(id, memberId)
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] lifted suspension %d of [%s]", memberId, id, suspension.MemberId)
+	}
+
+	c.wrapResponse(res)
+}
+
+// GetMemberSuspensions returns the suspension history of the member, to moderators and the member.
+func (c *APIController) GetMemberSuspensions() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	id := c.Input().Get("id")
+	if id == "" {
+		id = memberId
+	}
+	if id != memberId && !c.CheckPermission(object.DomainSite, object.PermissionModerate) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetMemberSuspensions(id)}
+	c.ServeJSON()
+}
+
+// GetActiveSuspensions returns the active suspensions of all the members.
+func (c *APIController) GetActiveSuspensions() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionModerate) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	limit, offset := c.getPage()
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetActiveSuspensions(limit, offset)}
+	c.ServeJSON()
+}
@@ -32,11 +32,23 @@ func (c *APIController) GetAllTabs() {
 }
 
 func (c *APIController) GetAllTabsAdmin() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	c.Data["json"] = object.GetAllTabsAdmin()
 	c.ServeJSON()
 }
 
 func (c *APIController) GetTabAdmin() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	id := c.Input().Get("id")
 
 	c.Data["json"] = object.GetTabAdmin(id)
@@ -44,6 +56,12 @@ func (c *APIController) GetTabAdmin() {
 }
 
 func (c *APIController) AddTab() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	var tabInfo object.AdminTabInfo
 	var resp Response
 
@@ -88,7 +106,7 @@ func (c *APIController) UpdateTab() {
 	var resp Response
 	var tabInfo object.AdminTabInfo
 
-	if !object.CheckModIdentity(c.GetSessionUser()) {
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
 		resp = Response{Status: "fail", Msg: "Unauthorized."}
 	}
 
@@ -114,9 +132,8 @@ func (c *APIController) UpdateTab() {
 
 func (c *APIController) DeleteTab() {
 	id := c.Input().Get("id")
-	memberId := c.GetSessionUser()
 
-	if !object.CheckModIdentity(memberId) {
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
 		resp := Response{Status: "fail", Msg: "Unauthorized."}
 		c.Data["json"] = resp
 		c.ServeJSON()
@@ -17,6 +17,7 @@ package controllers
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -27,10 +28,11 @@ import (
 )
 
 type NewTopicForm struct {
-	Title      string `json:"title"`
-	Body       string `json:"body"`
-	NodeId     string `json:"nodeId"`
-	EditorType string `json:"editorType"`
+	Title      string            `json:"title"`
+	Body       string            `json:"body"`
+	NodeId     string            `json:"nodeId"`
+	EditorType string            `json:"editorType"`
+	Fields     map[string]string `json:"fields"`
 }
 
 func (c *APIController) GetTopics() {
@@ -49,11 +51,17 @@ func (c *APIController) GetTopics() {
 		offset = page*limit - limit
 	}
 
-	c.Data["json"] = object.GetTopics(limit, offset)
+	c.Data["json"] = object.GetTopics(c.GetSessionUser(), limit, offset)
 	c.ServeJSON()
 }
 
 func (c *APIController) GetTopicsAdmin() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	limitStr := c.Input().Get("limit")
 	pageStr := c.Input().Get("page")
 
@@ -103,7 +111,7 @@ func (c *APIController) GetTopic() {
 	}
 
 	if memberId != "" {
-		topic.NodeModerator = object.CheckNodeModerator(memberId, topic.NodeId)
+		topic.NodeModerator = c.CheckPermission(object.GetNodeDomain(topic.NodeId), object.PermissionModerate)
 	}
 
 	c.Data["json"] = topic
@@ -111,6 +119,12 @@ func (c *APIController) GetTopic() {
 }
 
 func (c *APIController) GetTopicAdmin() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	idStr := c.Input().Get("id")
 
 	id := util.ParseInt(idStr)
@@ -120,6 +134,12 @@ func (c *APIController) GetTopicAdmin() {
 }
 
 func (c *APIController) UpdateTopic() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
 	idStr := c.Input().Get("id")
 
 	var topic object.Topic
@@ -140,10 +160,14 @@ func (c *APIController) AddTopic() {
 
 	memberId := c.GetSessionUser()
 	// check account status
-	if object.IsMuted(memberId) || object.IsForbidden(memberId) {
+	if object.IsMuted(memberId, "") || object.IsForbidden(memberId) {
 		c.mutedAccountResp(memberId)
 		return
 	}
+	if object.IsPendingApproval(memberId) {
+		c.pendingAccountResp(memberId)
+		return
+	}
 
 	var form NewTopicForm
 	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
@@ -166,6 +190,48 @@ func (c *APIController) AddTopic() {
 		return
 	}
 
+	if object.IsMuted(memberId, nodeId) {
+		c.nodeMutedAccountResp(memberId)
+		return
+	}
+
+	policy := object.GetEffectiveNodePolicy(nodeId)
+	if !policy.EditorTypeAllowed(editorType) {
+		resp := Response{Status: "fail", Msg: "This editor type is not allowed in this node."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	if !policy.AccountOldEnough(memberId) && !c.CheckPermission(object.GetNodeDomain(nodeId), object.PermissionPostRestricted) {
+		resp := Response{Status: "fail", Msg: "Your account is too new to post in this node."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	if !policy.TrustLevelEnough(memberId) && !c.CheckPermission(object.GetNodeDomain(nodeId), object.PermissionPostRestricted) {
+		resp := Response{Status: "fail", Msg: fmt.Sprintf("You need trust level %d to create topics in this node.", policy.MinTrustLevel)}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	if msg := object.CheckTrustLevelContent(memberId, title+"\n"+body); msg != "" {
+		resp := Response{Status: "fail", Msg: msg}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	fields, msg := object.GetNodeTemplate(nodeId).CheckTopicFields(form.Fields)
+	if msg != "" {
+		resp := Response{Status: "fail", Msg: msg}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
 	topic := object.Topic{
 		//Id:            util.IntToString(object.GetTopicId()),
 		Author:        memberId,
@@ -185,7 +251,7 @@ func (c *APIController) AddTopic() {
 	}
 
 	balance := object.GetMemberBalance(memberId)
-	if balance < object.CreateTopicCost {
+	if balance < policy.CreateTopicCost {
 		resp := Response{Status: "fail", Msg: "You don't have enough balance."}
 		c.Data["json"] = resp
 		c.ServeJSON()
@@ -195,15 +261,12 @@ func (c *APIController) AddTopic() {
 
 	//object.AddTopicNotification(topic.Id, c.GetSessionUser(), body)
 
-	err = json.Unmarshal(c.Ctx.Input.RequestBody, &topic)
-	if err != nil {
-		panic(err)
-	}
-
 	var resp Response
 	res, id := object.AddTopic(&topic)
 	if res {
-		object.CreateTopicConsumption(topic.Author, id)
+		object.CreateTopicConsumption(topic.Author, id, policy.CreateTopicCost)
+		object.AddTopicFields(id, fields)
+		object.UpdateTopicWatchLevel(topic.Author, id, 1)
 		object.AddTopicNotification(id, topic.Author, topic.Content)
 		resp = Response{Status: "ok", Msg: "success", Data: topic.Id}
 	} else {
@@ -219,6 +282,9 @@ func (c *APIController) UploadTopicPic() {
 		return
 	}
 	memberId := c.GetSessionUser()
+	if c.RequireTrustLevel(memberId, object.UploadFileTrustLevel, "upload files") {
+		return
+	}
 	fileBase64 := c.Ctx.Request.Form.Get("pic")
 	index := strings.Index(fileBase64, ",")
 	if index < 0 || fileBase64[0:index] != "data:image/png;base64" {
@@ -238,11 +304,10 @@ func (c *APIController) UploadTopicPic() {
 
 func (c *APIController) DeleteTopic() {
 	idStr := c.Input().Get("id")
-	memberId := c.GetSessionUser()
 
 	id := util.ParseInt(idStr)
 	nodeId := object.GetTopicNodeId(id)
-	if !object.CheckModIdentity(memberId) && !object.CheckNodeModerator(memberId, nodeId) {
+	if !c.CheckPermission(object.GetNodeDomain(nodeId), object.PermissionModerate) {
 		resp := Response{Status: "fail", Msg: "Unauthorized."}
 		c.Data["json"] = resp
 		c.ServeJSON()
@@ -311,7 +376,15 @@ func (c *APIController) GetTopicsByNode() {
 		offset = page*limit - limit
 	}
 
-	c.Data["json"] = object.GetTopicsWithNode(nodeId, limit, offset)
+	// filter by structured values: field.<name>=<value>
+	fields := map[string]string{}
+	for key, values := range c.Input() {
+		if strings.HasPrefix(key, "field.") && len(values) != 0 && values[0] != "" {
+			fields[strings.TrimPrefix(key, "field.")] = values[0]
+		}
+	}
+
+	c.Data["json"] = object.GetTopicsWithNode(nodeId, c.GetSessionUser(), fields, limit, offset)
 	c.ServeJSON()
 }
 
@@ -358,7 +431,7 @@ func (c *APIController) GetTopicsByTab() {
 		offset = page*limit - limit
 	}
 
-	c.Data["json"] = object.GetTopicsWithTab(tabId, limit, offset)
+	c.Data["json"] = object.GetTopicsWithTab(tabId, c.GetSessionUser(), limit, offset)
 	c.ServeJSON()
 }
 
@@ -409,7 +482,6 @@ func (c *APIController) UpdateTopicNode() {
 	}
 
 	var resp Response
-	memberId := c.GetSessionUser()
 	var form updateTopicNode
 	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
 	if err != nil {
@@ -418,7 +490,7 @@ func (c *APIController) UpdateTopicNode() {
 	id, nodeName, nodeId := form.Id, form.NodeName, form.NodeId
 
 	originalNode := object.GetTopicNodeId(id)
-	if !object.CheckModIdentity(memberId) && !object.CheckNodeModerator(memberId, originalNode) && object.GetTopicAuthor(id) != memberId {
+	if !c.checkAuthorOrPermission(object.GetTopicAuthor(id), object.GetNodeDomain(originalNode), object.PermissionModerate) {
 		resp = Response{Status: "fail", Msg: "Unauthorized."}
 		c.Data["json"] = resp
 		c.ServeJSON()
@@ -444,20 +516,40 @@ func (c *APIController) EditContent() {
 
 	editType := c.Input().Get("editType")
 	var resp Response
-	memberId := c.GetSessionUser()
 	if editType == "topic" {
 		var form editTopic
 		err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
 		if err != nil {
 			panic(err)
 		}
-		id, title, content, nodeId, editorType := form.Id, form.Title, form.Content, form.NodeId, form.EditorType
-		if !object.CheckModIdentity(memberId) && !object.CheckNodeModerator(memberId, nodeId) && object.GetTopicAuthor(id) != memberId {
+		id, title, content, editorType := form.Id, form.Title, form.Content, form.EditorType
+		topicInfo := object.GetTopicBasicInfo(id)
+		if topicInfo == nil || !c.checkAuthorOrPermission(topicInfo.Author, object.GetNodeDomain(topicInfo.NodeId), object.PermissionModerate) {
 			resp = Response{Status: "fail", Msg: "Unauthorized."}
 			c.Data["json"] = resp
 			c.ServeJSON()
 			return
 		}
+		// moderators could edit at any time, the authors only within the editable time of the node
+		if !object.GetTopicEditableStatus(c.GetSessionUser(), topicInfo.Author, topicInfo.NodeId, topicInfo.CreatedTime) {
+			resp = Response{Status: "fail", Msg: "The topic can't be edited any more."}
+			c.Data["json"] = resp
+			c.ServeJSON()
+			return
+		}
+		if !object.GetEffectiveNodePolicy(topicInfo.NodeId).EditorTypeAllowed(editorType) {
+			resp = Response{Status: "fail", Msg: "This editor type is not allowed in this node."}
+			c.Data["json"] = resp
+			c.ServeJSON()
+			return
+		}
+
+		if msg := object.CheckTrustLevelContent(c.GetSessionUser(), title+"\n"+content); msg != "" {
+			resp = Response{Status: "fail", Msg: msg}
+			c.Data["json"] = resp
+			c.ServeJSON()
+			return
+		}
 
 		topic := object.Topic{
 			Id:         id,
@@ -475,12 +567,38 @@ func (c *APIController) EditContent() {
 			panic(err)
 		}
 		id, content, editorType := form.Id, form.Content, form.EditorType
-		if !object.CheckModIdentity(memberId) && object.GetReplyAuthor(id) != memberId {
+		replyInfo := object.GetReply(id)
+		nodeId := ""
+		if replyInfo != nil {
+			nodeId = object.GetTopicNodeId(replyInfo.TopicId)
+		}
+		if replyInfo == nil || !c.checkAuthorOrPermission(replyInfo.Author, object.GetNodeDomain(nodeId), object.PermissionModerate) {
 			resp = Response{Status: "fail", Msg: "Unauthorized."}
 			c.Data["json"] = resp
 			c.ServeJSON()
 			return
 		}
+		policy := object.GetEffectiveNodePolicy(nodeId)
+		// moderators could edit at any time, the authors only within the editable time of the node
+		if !c.CheckPermission(object.GetNodeDomain(nodeId), object.PermissionModerate) && !object.GetReplyEditableStatus(c.GetSessionUser(), replyInfo.Author, replyInfo.CreatedTime, policy.ReplyEditableTime) {
+			resp = Response{Status: "fail", Msg: "The reply can't be edited any more."}
+			c.Data["json"] = resp
+			c.ServeJSON()
+			return
+		}
+		if !policy.EditorTypeAllowed(editorType) {
+			resp = Response{Status: "fail", Msg: "This editor type is not allowed in this node."}
+			c.Data["json"] = resp
+			c.ServeJSON()
+			return
+		}
+
+		if msg := object.CheckTrustLevelContent(c.GetSessionUser(), content); msg != "" {
+			resp = Response{Status: "fail", Msg: msg}
+			c.Data["json"] = resp
+			c.ServeJSON()
+			return
+		}
 
 		reply := object.Reply{
 			Id:         id,
@@ -510,7 +628,7 @@ func (c *APIController) TopTopic() {
 	var res bool
 
 	nodeId := object.GetTopicNodeId(id)
-	if object.CheckModIdentity(memberId) || object.CheckNodeModerator(memberId, nodeId) {
+	if c.CheckPermission(object.GetNodeDomain(nodeId), object.PermissionModerate) {
 		//timeStr := c.Input().Get("time")
 		//time := util.ParseInt(timeStr)
 		//date := util.GetTimeMinute(time)
@@ -548,14 +666,13 @@ func (c *APIController) CancelTopTopic() {
 	}
 
 	idStr := c.Input().Get("id")
-	memberId := c.GetSessionUser()
 
 	id := util.ParseInt(idStr)
 	var resp Response
 	var res bool
 
 	nodeId := object.GetTopicNodeId(id)
-	if object.CheckModIdentity(memberId) || object.CheckNodeModerator(memberId, nodeId) {
+	if c.CheckPermission(object.GetNodeDomain(nodeId), object.PermissionModerate) {
 		topType := c.Input().Get("topType")
 		res = object.ChangeTopicTopExpiredTime(id, "", topType)
 	} else {
@@ -569,3 +686,46 @@ func (c *APIController) CancelTopTopic() {
 	c.Data["json"] = resp
 	c.ServeJSON()
 }
+
+// GetTopicWatchLevel gets member's watch level of the topic, 1-3 means: watching, normal, muted.
+func (c *APIController) GetTopicWatchLevel() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	idStr := c.Input().Get("id")
+
+	id := util.ParseInt(idStr)
+	res := object.GetTopicWatchLevel(memberId, id, object.GetTopicAuthor(id))
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: res}
+	c.ServeJSON()
+}
+
+// UpdateTopicWatchLevel updates member's watch level of the topic, 1-3 means: watching, normal, muted.
+func (c *APIController) UpdateTopicWatchLevel() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	idStr := c.Input().Get("id")
+	watchLevelStr := c.Input().Get("watchLevel")
+
+	id := util.ParseInt(idStr)
+	watchLevel := util.ParseInt(watchLevelStr)
+
+	var resp Response
+	if object.GetTopicBasicInfo(id) == nil {
+		resp = Response{Status: "fail", Msg: "Topic doesn't exist."}
+	} else if watchLevel < 1 || watchLevel > 3 {
+		resp = Response{Status: "fail", Msg: "param wrong"}
+	} else {
+		res := object.UpdateTopicWatchLevel(memberId, id, watchLevel)
+		resp = Response{Status: "ok", Msg: "success", Data: res}
+	}
+
+	c.Data["json"] = resp
+	c.ServeJSON()
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/util"
+)
+
+// RequireTrustLevel responds with an error and returns true if the member's trust level is lower than level.
+func (c *APIController) RequireTrustLevel(memberId string, level int, action string) bool {
+	if object.GetMemberTrustLevel(memberId) >= level {
+		return false
+	}
+
+	resp := Response{Status: "fail", Msg: fmt.Sprintf("You need trust level %d to %s.", level, action)}
+	c.Data["json"] = resp
+	c.ServeJSON()
+	return true
+}
+
+// GetTrustLevel returns the trust level of the member and the requirements of all the levels.
+func (c *APIController) GetTrustLevel() {
+	id := c.Input().Get("id")
+	if id == "" {
+		id = c.GetSessionUser()
+	}
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetMemberTrustLevel(id), Data2: object.TrustLevelRequirements}
+	c.ServeJSON()
+}
+
+// UpdateMemberTrustLevel pins the trust level of the member, or lets it be computed again with pinned=false.
+func (c *APIController) UpdateMemberTrustLevel() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	var form trustLevelForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	var res bool
+	if form.Pinned {
+		if !object.IsValidTrustLevel(form.Level) {
+			resp := Response{Status: "fail", Msg: "Invalid trust level."}
+			c.Data["json"] = resp
+			c.ServeJSON()
+			return
+		}
+		res = object.PinMemberTrustLevel(form.MemberId, form.Level)
+	} else {
+		res = object.UnpinMemberTrustLevel(form.MemberId)
+	}
+
+	if res {
+		util.LogInfo(c.Ctx, "API: [%s] updated the trust level of [%s], level: %d, pinned: %t", memberId, form.MemberId, form.Level, form.Pinned)
+	}
+
+	c.wrapResponse(res)
+}
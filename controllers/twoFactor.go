@@ -0,0 +1,254 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/astaxie/beego"
+
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/util"
+)
+
+// SigninTwoFactor completes the sign in kept pending by Signin() or Auth() with a code or a recovery code.
+func (c *APIController) SigninTwoFactor() {
+	var resp Response
+
+	memberId, _ := c.GetSession("twoFactorMember").(string)
+	pendingTime, _ := c.GetSession("twoFactorTime").(string)
+	attempts, _ := c.GetSession("twoFactorAttempts").(int)
+	if memberId == "" || pendingTime < util.GetTimeMinute(-object.TwoFactorPendingTime) {
+		c.clearTwoFactorSession()
+		resp = Response{Status: "error", Msg: "Please sign in again"}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	var form twoFactorForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	// failures count against the account too, signing in again doesn't reset them
+	unlockTime := object.GetAuthLockTime(object.AuthActionTwoFactor, memberId, object.MaxTwoFactorAttempts)
+	if unlockTime != "" {
+		c.clearTwoFactorSession()
+		resp = Response{Status: "error", Msg: "errorSigninLocked", Data: unlockTime}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	if !object.VerifyMemberTwoFactor(memberId, form.Code, form.RecoveryCode) {
+		object.AddAuthFailure(object.AuthActionTwoFactor, memberId)
+		util.LogWarning(c.Ctx, "API: two-factor signin of [%s] failed", memberId)
+		attempts++
+		if attempts >= object.MaxTwoFactorAttempts {
+			c.clearTwoFactorSession()
+		} else {
+			c.SetSession("twoFactorAttempts", attempts)
+		}
+		resp = Response{Status: "error", Msg: "Two-factor code error"}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	// check account status
+	if object.IsForbidden(memberId) {
+		c.clearTwoFactorSession()
+		c.forbiddenAccountResp(memberId)
+		return
+	}
+
+	object.ClearAuthFailures(object.AuthActionTwoFactor, memberId)
+	if target, _ := c.GetSession("twoFactorTarget").(string); target != "" {
+		object.ClearAuthFailures(object.AuthActionSignin, target)
+	}
+
+	c.clearTwoFactorSession()
+	c.SetSessionUser(memberId)
+
+	util.LogInfo(c.Ctx, "API: [%s] signed in", memberId)
+	resp = Response{Status: "ok", Msg: "success", Data: memberId}
+	c.Data["json"] = resp
+	c.ServeJSON()
+}
+
+func (c *APIController) clearTwoFactorSession() {
+	c.DelSession("twoFactorMember")
+	c.DelSession("twoFactorTime")
+	c.DelSession("twoFactorAttempts")
+	c.DelSession("twoFactorTarget")
+}
+
+func (c *APIController) GetTwoFactorStatus() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	status := twoFactorStatus{
+		Enabled:         object.GetMemberTotpEnabled(memberId),
+		RecoveryCodeNum: object.GetRecoveryCodeNum(memberId),
+		Required:        object.GetRequireModeratorTwoFactor() && object.HasModeratorRight(memberId),
+	}
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: status}
+	c.ServeJSON()
+}
+
+// SetupTwoFactor generates a new secret, returns it with the provisioning uri for the authenticator app.
+func (c *APIController) SetupTwoFactor() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	if object.GetMemberTotpEnabled(memberId) {
+		resp := Response{Status: "fail", Msg: "Two-factor authentication is already enabled."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	secret := util.GenerateTotpSecret()
+	object.SetMemberTotpSecret(memberId, secret)
+	uri := util.GetTotpUri(beego.AppConfig.String("appname"), memberId, secret)
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: secret, Data2: uri}
+	c.ServeJSON()
+}
+
+// EnableTwoFactor verifies the first code of the secret from SetupTwoFactor(), returns the recovery codes.
+func (c *APIController) EnableTwoFactor() {
+	if c.RequireLogin() {
+		return
+	}
+
+	var form twoFactorForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	memberId := c.GetSessionUser()
+	if object.GetMemberTotpEnabled(memberId) {
+		resp := Response{Status: "fail", Msg: "Two-factor authentication is already enabled."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	if !object.VerifyMemberTotp(memberId, form.Code) {
+		resp := Response{Status: "fail", Msg: "Two-factor code error"}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	object.EnableMemberTotp(memberId)
+	codes := object.GenerateRecoveryCodes(memberId)
+
+	util.LogInfo(c.Ctx, "API: [%s] enabled two-factor authentication", memberId)
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: codes}
+	c.ServeJSON()
+}
+
+func (c *APIController) DisableTwoFactor() {
+	if c.RequireLogin() {
+		return
+	}
+
+	var form twoFactorForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	memberId := c.GetSessionUser()
+	if !object.GetMemberTotpEnabled(memberId) {
+		resp := Response{Status: "fail", Msg: "Two-factor authentication is not enabled."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	if !object.VerifyMemberTwoFactor(memberId, form.Code, form.RecoveryCode) {
+		resp := Response{Status: "fail", Msg: "Two-factor code error"}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	res := object.DisableMemberTotp(memberId)
+
+	util.LogInfo(c.Ctx, "API: [%s] disabled two-factor authentication", memberId)
+	c.wrapResponse(res)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes, the old ones can't be used any more.
+func (c *APIController) RegenerateRecoveryCodes() {
+	if c.RequireLogin() {
+		return
+	}
+
+	var form twoFactorForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	memberId := c.GetSessionUser()
+	if !object.GetMemberTotpEnabled(memberId) || !object.VerifyMemberTotp(memberId, form.Code) {
+		resp := Response{Status: "fail", Msg: "Two-factor code error"}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	codes := object.GenerateRecoveryCodes(memberId)
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: codes}
+	c.ServeJSON()
+}
+
+func (c *APIController) GetRequireModeratorTwoFactor() {
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetRequireModeratorTwoFactor()}
+	c.ServeJSON()
+}
+
+// UpdateRequireModeratorTwoFactor sets whether admins and node moderators must enable two-factor authentication to use their rights.
+func (c *APIController) UpdateRequireModeratorTwoFactor() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	require := c.Input().Get("require") == "true"
+	// the admin would lose the rights right away otherwise
+	if require && !object.GetMemberTotpEnabled(memberId) {
+		resp := Response{Status: "fail", Msg: "Please enable two-factor authentication for your own account first."}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	c.wrapResponse(object.UpdateRequireModeratorTwoFactor(require))
+}
@@ -14,50 +14,67 @@
 
 package controllers
 
-import "github.com/casbin/casnode/object"
+import (
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/service"
+)
 
-type userInfoFromGoogle struct {
-	Picture string `json:"picture"`
-	Email   string `json:"email"`
+type authResponse struct {
+	IsAuthenticated bool   `json:"isAuthenticated"`
+	IsSignedUp      bool   `json:"isSignedUp"`
+	Email           string `json:"email"`
+	Avatar          string `json:"avatar"`
+	Addition        string `json:"addition"`
+}
+
+// linkedIdentity is an identity authenticated by a provider, kept in the session until it's linked with a member.
+type linkedIdentity struct {
+	Provider string `json:"provider"`
+	service.UserInfo
 }
 
-type userEmailFromGithub struct {
-	Email      string `json:"email"`
-	Primary    bool   `json:"primary"`
-	Verified   bool   `json:"verified"`
-	Visibility string `json:"visibility"`
+type twoFactorForm struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
 }
 
-type userInfoFromGithub struct {
-	Login     string `json:"login"`
-	AvatarUrl string `json:"avatar_url"`
+type deleteAccountForm struct {
+	Password     string `json:"password"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
 }
 
-type userInfoFromQQ struct {
-	Ret       int    `json:"ret"`
-	Nickname  string `json:"nickname"`
-	AvatarUrl string `json:"figureurl_qq_1"`
+type unlinkAccountForm struct {
+	Provider     string `json:"provider"`
+	Password     string `json:"password"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
 }
 
-type userInfoFromWeChat struct {
-	Nickname  string `json:"nickname"`
-	AvatarUrl string `json:"headimgurl"`
+type contactChangeForm struct {
+	Type           string `json:"type"` // email or phone
+	Value          string `json:"value"`
+	AreaCode       string `json:"areaCode"`
+	Password       string `json:"password"`
+	ValidateCode   string `json:"validateCode"`
+	ValidateCodeId string `json:"validateCodeId"`
 }
 
-type GetAccessTokenRespFromWeChat struct {
-	AccessToken  string  `json:"access_token"`
-	ExpiresIn    float64 `json:"expires_in"`
-	RefreshToken string  `json:"refresh_token"`
-	Openid       string  `json:"openid"`
-	Scope        string  `json:"scope"`
+type usernameChangeForm struct {
+	NewName string `json:"newName"`
+	Reason  string `json:"reason"`
 }
 
-type authResponse struct {
-	IsAuthenticated bool   `json:"isAuthenticated"`
-	IsSignedUp      bool   `json:"isSignedUp"`
-	Email           string `json:"email"`
-	Avatar          string `json:"avatar"`
-	Addition        string `json:"addition"`
+type newAccessTokenForm struct {
+	Name       string `json:"name"`
+	Scope      string `json:"scope"`
+	ExpireDays int    `json:"expireDays"`
+}
+
+type twoFactorStatus struct {
+	Enabled         bool `json:"enabled"`
+	RecoveryCodeNum int  `json:"recoveryCodeNum"`
+	Required        bool `json:"required"`
 }
 
 type stsTokenResponse struct {
@@ -153,6 +170,31 @@ type fileNumResp struct {
 	MaxNum int `json:"maxNum"`
 }
 
+type trustLevelForm struct {
+	MemberId string `json:"memberId"`
+	Level    int    `json:"level"`
+	Pinned   bool   `json:"pinned"`
+}
+
+type memberRoleForm struct {
+	MemberId string `json:"memberId"`
+	Role     string `json:"role"`
+	Domain   string `json:"domain"`
+}
+
+type memberBadgeForm struct {
+	MemberId string `json:"memberId"`
+	BadgeId  string `json:"badgeId"`
+}
+
+type suspensionForm struct {
+	MemberId string `json:"memberId"`
+	Type     string `json:"type"`
+	NodeId   string `json:"nodeId"`
+	Reason   string `json:"reason"`
+	Duration int    `json:"duration"` // hours, 0 means permanent
+}
+
 type addNodeModerator struct {
 	NodeId   string `json:"nodeId"`
 	MemberId string `json:"memberId"`
@@ -0,0 +1,144 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/casbin/casnode/object"
+	"github.com/casbin/casnode/util"
+)
+
+// RequestUsernameChange adds a rename request of the member, to be approved by admin.
+func (c *APIController) RequestUsernameChange() {
+	if c.RequireLogin() {
+		return
+	}
+
+	var form usernameChangeForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		panic(err)
+	}
+
+	var resp Response
+	memberId := c.GetSessionUser()
+	if msg := object.CheckUsernameChangeAllowed(memberId); msg != "" {
+		resp = Response{Status: "fail", Msg: msg}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+	if object.UserNamingRestrictions && !util.IsValidUsername(form.NewName) {
+		resp = Response{Status: "fail", Msg: "You can only use numbers, letters and underline in your username, and the length is between 4 and 20 characters"}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+	if form.NewName == "" || object.IsUsernameTaken(form.NewName, memberId) || object.HasMember(form.NewName) {
+		resp = Response{Status: "fail", Msg: "The username has been taken"}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+	if len(form.Reason) > 500 {
+		resp = Response{Status: "fail", Msg: "The reason is too long"}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	change := object.UsernameChange{
+		MemberId:    memberId,
+		OldName:     memberId,
+		NewName:     form.NewName,
+		Reason:      form.Reason,
+		State:       object.UsernameChangePending,
+		CreatedTime: util.GetCurrentTime(),
+	}
+
+	c.wrapResponse(object.AddUsernameChange(&change))
+}
+
+// GetAccountUsernameChanges returns the rename requests and history of the member.
+func (c *APIController) GetAccountUsernameChanges() {
+	if c.RequireLogin() {
+		return
+	}
+
+	memberId := c.GetSessionUser()
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetMemberUsernameChanges(memberId)}
+	c.ServeJSON()
+}
+
+func (c *APIController) GetUsernameChanges() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	limitStr := c.Input().Get("limit")
+	pageStr := c.Input().Get("page")
+	state := c.Input().Get("state")
+	defaultLimit := object.DefaultMemberAdminPageNum
+
+	var limit, offset int
+	if len(limitStr) != 0 {
+		limit = util.ParseInt(limitStr)
+	} else {
+		limit = defaultLimit
+	}
+	if len(pageStr) != 0 {
+		page := util.ParseInt(pageStr)
+		offset = page*limit - limit
+	}
+
+	c.Data["json"] = Response{Status: "ok", Msg: "success", Data: object.GetUsernameChanges(state, limit, offset)}
+	c.ServeJSON()
+}
+
+// ApproveUsernameChange renames the member of the request.
+func (c *APIController) ApproveUsernameChange() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	id := util.ParseInt(c.Input().Get("id"))
+	res, msg := object.ApproveUsernameChange(id, memberId)
+	if !res {
+		resp := Response{Status: "fail", Msg: msg}
+		c.Data["json"] = resp
+		c.ServeJSON()
+		return
+	}
+
+	change := object.GetUsernameChange(id)
+	util.LogInfo(c.Ctx, "API: [%s] renamed member [%s] to [%s]", memberId, change.OldName, change.NewName)
+	c.wrapResponse(res)
+}
+
+func (c *APIController) RejectUsernameChange() {
+	memberId := c.GetSessionUser()
+	if !c.CheckPermission(object.DomainSite, object.PermissionManage) {
+		c.RequireAdmin(memberId)
+		return
+	}
+
+	id := util.ParseInt(c.Input().Get("id"))
+	c.wrapResponse(object.RejectUsernameChange(id, memberId))
+}
@@ -15,15 +15,18 @@
 package controllers
 
 import (
-	"crypto/md5"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"time"
 
 	"golang.org/x/net/proxy"
 
 	"github.com/casbin/casnode/object"
 	"github.com/casbin/casnode/service"
+	"github.com/casbin/casnode/util"
 )
 
 var httpClient *http.Client
@@ -54,22 +57,72 @@ func InitHttpClient() {
 	//println("Response status: %s", resp.Status)
 }
 
-func UploadAvatarToOSS(avatar, memberId string) string {
-	if len(avatar) == 0 {
-		data := []byte(memberId)
-		has := md5.Sum(data)
-		memberMd5 := fmt.Sprintf("%x", has)
-		avatar = "https://www.gravatar.com/avatar/" + memberMd5 + "?d=retro"
-	}
+// InitIdProviders loads the identity providers, they share the http client of the controllers.
+func InitIdProviders() {
+	service.InitIdProviders(httpClient)
+}
 
-	response, err := httpClient.Get(avatar)
+// fetchAvatar downloads the avatar of the identity provider and processes it, returns nil if it's not a valid image.
+func fetchAvatar(url string) map[int][]byte {
+	response, err := httpClient.Get(url)
 	if err != nil {
-		panic(err)
+		return nil
 	}
 	defer response.Body.Close()
-	avatarInfo, err := ioutil.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(response.Body, int64(object.MaxAvatarFileSize)<<20))
+	if err != nil {
+		return nil
+	}
+
+	images, err := util.ProcessAvatar(data, object.AvatarSizes)
+	if err != nil {
+		return nil
+	}
+	return images
+}
+
+// saveAvatar uploads the processed avatar, the largest size is saved as <timestamp>.png and the others
+// as <timestamp>_<size>.png next to it. Returns the URL of the largest one and the URLs by size.
+func saveAvatar(images map[int][]byte, memberId string) (string, map[int]string) {
+	maxSize := 0
+	for size := range images {
+		if size > maxSize {
+			maxSize = size
+		}
+	}
 
-	avatarURL := service.UploadAvatarToOSS(avatarInfo, memberId)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	urls := map[int]string{}
+	for size, data := range images {
+		name := timestamp
+		if size != maxSize {
+			name = fmt.Sprintf("%s_%d", timestamp, size)
+		}
+		urls[size] = service.UploadFileToOSS(data, "/"+memberId+"/avatar/"+name+".png")
+	}
+
+	return urls[maxSize], urls
+}
+
+// UploadAvatarToOSS saves the avatar of the identity provider as the member's avatar, or a generated
+// identicon if there is none, returns the avatar URL.
+func UploadAvatarToOSS(avatar, memberId string) string {
+	var images map[int][]byte
+	if avatar != "" {
+		images = fetchAvatar(avatar)
+	}
+
+	if images == nil {
+		images = map[int][]byte{}
+		for _, size := range object.AvatarSizes {
+			images[size] = util.GenerateIdenticon(memberId, size)
+		}
+	}
 
+	avatarURL, _ := saveAvatar(images, memberId)
 	return avatarURL
 }
@@ -17,6 +17,7 @@ package controllers
 import (
 	"github.com/casbin/casnode/object"
 	"github.com/casbin/casnode/service"
+	"github.com/casbin/casnode/util"
 )
 
 // GetCaptcha gets captcha.
@@ -36,12 +37,24 @@ func (c *APIController) GetValidateCode() {
 	information := c.Input().Get("information")
 	verifyType := c.Input().Get("type") // verify type: 1: phone, 2: email.
 
-	id, code := object.GetNewValidateCode(information)
+	ip := util.GetClientIp(c.Ctx.Request)
+	if verifyType != "1" && verifyType != "2" {
+		c.Data["json"] = Response{Status: "error", Msg: "Unknown verify type"}
+		c.ServeJSON()
+		return
+	}
+	if msg := object.CheckValidateCodeQuota(information, ip); msg != "" {
+		c.Data["json"] = Response{Status: "error", Msg: msg}
+		c.ServeJSON()
+		return
+	}
+
+	id, code := object.GetNewValidateCode(information, ip)
 
 	if verifyType == "1" {
 		service.SendSms(information, code)
 	} else {
-		service.SendRegistrationMail(information, code)
+		object.EnqueueMail(service.NewRegistrationMail(information, code))
 	}
 
 	resp := Response{Status: "ok", Msg: "success", Data: id}
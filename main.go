@@ -30,6 +30,7 @@ import (
 func main() {
 	object.InitAdapter()
 	controllers.InitHttpClient()
+	controllers.InitIdProviders()
 	service.InitOSS()
 	util.InitSegmenter()
 	object.InitForumBasicInfo()
@@ -37,7 +38,7 @@ func main() {
 	beego.InsertFilter("*", beego.BeforeRouter, cors.Allow(&cors.Options{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "PUT", "PATCH"},
-		AllowHeaders:     []string{"Origin"},
+		AllowHeaders:     []string{"Origin", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 	}))
@@ -59,6 +60,7 @@ func main() {
 
 	//controllers.InitBeegoSession()
 	object.InitTimer()
+	object.InitMailWorker()
 
 	beego.Run("0.0.0.0:" + port)
 }
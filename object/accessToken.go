@@ -0,0 +1,131 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/hex"
+
+	"github.com/casbin/casnode/util"
+)
+
+// AccessToken is a personal token for scripted API access, only the hash of the token is stored.
+// Scope could be read (GET requests only), post (everything a member can do) or moderate (moderator rights as well).
+// An empty ExpireTime means the token never expires.
+type AccessToken struct {
+	Id           int    `xorm:"int notnull pk autoincr" json:"id"`
+	MemberId     string `xorm:"varchar(100) index" json:"memberId"`
+	Name         string `xorm:"varchar(100)" json:"name"`
+	TokenHash    string `xorm:"varchar(100) unique" json:"-"`
+	Scope        string `xorm:"varchar(20)" json:"scope"`
+	CreatedTime  string `xorm:"varchar(40)" json:"createdTime"`
+	ExpireTime   string `xorm:"varchar(40)" json:"expireTime"`
+	LastUsedTime string `xorm:"varchar(40)" json:"lastUsedTime"`
+	LastUsedIp   string `xorm:"varchar(100)" json:"lastUsedIp"`
+	Revoked      bool   `xorm:"bool" json:"revoked"`
+}
+
+const (
+	TokenScopeRead     = "read"
+	TokenScopePost     = "post"
+	TokenScopeModerate = "moderate"
+
+	accessTokenPrefix = "cnt_"
+)
+
+func IsValidTokenScope(scope string) bool {
+	return scope == TokenScopeRead || scope == TokenScopePost || scope == TokenScopeModerate
+}
+
+// AddAccessToken saves the token and returns the plain token, which is only shown once.
+func AddAccessToken(token *AccessToken) (bool, string) {
+	value := accessTokenPrefix + hex.EncodeToString(util.GetRandomBytes(20))
+	token.TokenHash = util.GetSha256Hash(value)
+
+	affected, err := adapter.engine.Insert(token)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0, value
+}
+
+func GetMemberAccessTokens(memberId string) []*AccessToken {
+	tokens := []*AccessToken{}
+	err := adapter.engine.Where("member_id = ?", memberId).Desc("created_time").Find(&tokens)
+	if err != nil {
+		panic(err)
+	}
+
+	return tokens
+}
+
+// GetMemberValidAccessTokenNum returns the number of the member's tokens that are neither revoked nor expired.
+func GetMemberValidAccessTokenNum(memberId string) int {
+	total, err := adapter.engine.Where("member_id = ?", memberId).And("revoked = ?", false).
+		And("(expire_time = '' or expire_time > ?)", util.GetCurrentTime()).Count(&AccessToken{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(total)
+}
+
+// GetValidAccessToken returns the token by its plain value, nil if it doesn't exist, is revoked or expired.
+func GetValidAccessToken(value string) *AccessToken {
+	token := AccessToken{TokenHash: util.GetSha256Hash(value)}
+	existed, err := adapter.engine.Get(&token)
+	if err != nil {
+		panic(err)
+	}
+
+	if !existed || token.Revoked {
+		return nil
+	}
+	if token.ExpireTime != "" && token.ExpireTime <= util.GetCurrentTime() {
+		return nil
+	}
+	return &token
+}
+
+func UpdateAccessTokenLastUsed(id int, ip string) bool {
+	token := AccessToken{LastUsedTime: util.GetCurrentTime(), LastUsedIp: ip}
+	affected, err := adapter.engine.Id(id).Cols("last_used_time, last_used_ip").Update(&token)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func RevokeAccessToken(memberId string, id int) bool {
+	token := AccessToken{Revoked: true}
+	affected, err := adapter.engine.Id(id).Where("member_id = ?", memberId).Cols("revoked").Update(&token)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// RevokeMemberAccessTokens revokes all the tokens of the member.
+func RevokeMemberAccessTokens(memberId string) bool {
+	token := AccessToken{Revoked: true}
+	affected, err := adapter.engine.Where("member_id = ?", memberId).Cols("revoked").Update(&token)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
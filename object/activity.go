@@ -0,0 +1,171 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"strings"
+
+	"github.com/casbin/casnode/util"
+)
+
+// Activity types of the timelines.
+const (
+	ActivityTopic        = "topic"
+	ActivityReply        = "reply"
+	ActivityThanksTopic  = "thanksTopic"
+	ActivityThanksReply  = "thanksReply"
+	ActivityFavoriteNode = "favoriteNode"
+	ActivityFollow       = "follow"
+)
+
+// Activity is an item of the timelines, ObjectId is the id of the topic, reply, node or member
+// depending on Type. Title and Content describe the object.
+type Activity struct {
+	Type        string `json:"type"`
+	MemberId    string `json:"memberId"`
+	ObjectId    string `json:"objectId"`
+	TopicId     int    `json:"topicId"`
+	CreatedTime string `json:"createdTime"`
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+}
+
+// activitySources are the queries of the activity types. Each of them selects the activities of the
+// members in the IN clause, the privacy settings decide which members are passed to which query.
+var activitySources = []struct {
+	activityType, query string
+}{
+	{ActivityTopic, "SELECT 'topic' AS type, author AS member_id, CAST(id AS CHAR) AS object_id, id AS topic_id, created_time FROM topic WHERE deleted = 0 AND author IN (%s)"},
+	{ActivityReply, "SELECT 'reply' AS type, author AS member_id, CAST(id AS CHAR) AS object_id, topic_id, created_time FROM reply WHERE deleted = 0 AND author IN (%s)"},
+	{ActivityThanksTopic, "SELECT 'thanksTopic' AS type, receiver_id AS member_id, CAST(object_id AS CHAR) AS object_id, object_id AS topic_id, created_time FROM consumption_record WHERE consumption_type = 4 AND receiver_id IN (%s)"},
+	{ActivityThanksReply, "SELECT 'thanksReply' AS type, receiver_id AS member_id, CAST(object_id AS CHAR) AS object_id, 0 AS topic_id, created_time FROM consumption_record WHERE consumption_type = 5 AND receiver_id IN (%s)"},
+	{ActivityFavoriteNode, "SELECT 'favoriteNode' AS type, member_id, object_id, 0 AS topic_id, created_time FROM favorites WHERE favorites_type = 3 AND member_id IN (%s)"},
+	{ActivityFollow, "SELECT 'follow' AS type, member_id, object_id, 0 AS topic_id, created_time FROM favorites WHERE favorites_type = 2 AND member_id IN (%s)"},
+}
+
+// getActivities returns a page of the activities, the topics and replies of all the members are included,
+// the thanks and favorites only of the members in thankers and favoriters.
+func getActivities(members, thankers, favoriters []string, limit int, offset int) []*Activity {
+	activities := []*Activity{}
+
+	parts := []string{}
+	args := []interface{}{}
+	for _, source := range activitySources {
+		ids := members
+		switch source.activityType {
+		case ActivityThanksTopic, ActivityThanksReply:
+			ids = thankers
+		case ActivityFavoriteNode, ActivityFollow:
+			ids = favoriters
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		parts = append(parts, strings.Replace(source.query, "%s", strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "), 1))
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	if len(parts) == 0 {
+		return activities
+	}
+
+	sql := "SELECT * FROM (" + strings.Join(parts, " UNION ALL ") + ") AS activity ORDER BY created_time DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+	err := adapter.engine.SQL(sql, args...).Find(&activities)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, activity := range activities {
+		fillActivity(activity)
+	}
+	return activities
+}
+
+// fillActivity fills the title and content of the activity's object.
+func fillActivity(activity *Activity) {
+	switch activity.Type {
+	case ActivityReply, ActivityThanksReply:
+		reply := GetReply(util.ParseInt(activity.ObjectId))
+		if reply == nil || reply.Deleted {
+			return
+		}
+		activity.TopicId = reply.TopicId
+		activity.Title = GetReplyTopicTitle(reply.TopicId)
+		activity.Content = reply.Content
+	case ActivityTopic, ActivityThanksTopic:
+		activity.Title = GetTopicTitle(activity.TopicId)
+	case ActivityFavoriteNode:
+		if node := GetNode(activity.ObjectId); node != nil {
+			activity.Title = node.Name
+		}
+	case ActivityFollow:
+		activity.Title = activity.ObjectId
+	}
+}
+
+// GetMemberActivities returns the timeline of the member. The thanks and favorites are only included
+// if the member has made them public, or the viewer is the member.
+func GetMemberActivities(memberId, viewerId string, limit int, offset int) []*Activity {
+	member := GetMember(memberId)
+	if member == nil {
+		return []*Activity{}
+	}
+
+	members := []string{memberId}
+	thankers, favoriters := []string{}, []string{}
+	if member.ThanksPublic || memberId == viewerId {
+		thankers = members
+	}
+	if member.FavoritesPublic || memberId == viewerId {
+		favoriters = members
+	}
+
+	return getActivities(members, thankers, favoriters, limit, offset)
+}
+
+// GetFollowingActivities returns the activities of the members followed by the member, except the blocked ones.
+func GetFollowingActivities(memberId string, limit int, offset int) []*Activity {
+	followed := []*Member{}
+	err := adapter.engine.Table("member").Join("INNER", "favorites", "favorites.object_id = member.id").
+		Where("favorites.member_id = ?", memberId).And("favorites.favorites_type = ?", 2).
+		Cols("member.id, member.thanks_public, member.favorites_public").Find(&followed)
+	if err != nil {
+		panic(err)
+	}
+
+	blocked := map[string]bool{}
+	for _, id := range GetBlockedMemberIds(memberId) {
+		blocked[id] = true
+	}
+
+	members, thankers, favoriters := []string{}, []string{}, []string{}
+	for _, member := range followed {
+		if blocked[member.Id] {
+			continue
+		}
+		members = append(members, member.Id)
+		if member.ThanksPublic {
+			thankers = append(thankers, member.Id)
+		}
+		if member.FavoritesPublic {
+			favoriters = append(favoriters, member.Id)
+		}
+	}
+
+	return getActivities(members, thankers, favoriters, limit, offset)
+}
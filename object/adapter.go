@@ -33,6 +33,7 @@ type Session struct {
 
 func InitAdapter() {
 	adapter = NewAdapter("mysql", beego.AppConfig.String("dataSourceName"))
+	initEnforcer()
 }
 
 // Adapter represents the MySQL adapter for policy storage.
@@ -175,4 +176,88 @@ func (a *Adapter) createTable() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = a.engine.Sync2(new(TopicWatch))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(TopicField))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(LinkedAccount))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(RecoveryCode))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(AccessToken))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(SessionRecord))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(AuthAttempt))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(UsernameChange))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(MemberBlock))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(InviteCode))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(CasbinRule))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(Badge))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(MemberBadge))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(Suspension))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(NotificationPreference))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(QueuedMail))
+	if err != nil {
+		panic(err)
+	}
+
+	a.migrateLinkedAccounts()
+	a.migratePermissions()
+	a.migrateSuspensions()
 }
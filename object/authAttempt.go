@@ -0,0 +1,112 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"strings"
+
+	"github.com/casbin/casnode/util"
+)
+
+// AuthAttempt records a failed authentication, Target is the account or the IP address the failure counts against.
+type AuthAttempt struct {
+	Id          int    `xorm:"int notnull pk autoincr" json:"id"`
+	Action      string `xorm:"varchar(20) index(action_target)" json:"action"`
+	Target      string `xorm:"varchar(200) index(action_target)" json:"target"`
+	CreatedTime string `xorm:"varchar(40) index" json:"createdTime"`
+}
+
+const (
+	AuthActionSignin    = "signin"
+	AuthActionSigninIp  = "signinIp"
+	AuthActionTwoFactor = "twoFactor"
+)
+
+// GetSigninTarget normalizes the information used to sign in, so failures count against the same account.
+func GetSigninTarget(information string) string {
+	return strings.ToLower(strings.TrimSpace(information))
+}
+
+func AddAuthFailure(action, target string) bool {
+	attempt := AuthAttempt{
+		Action:      action,
+		Target:      target,
+		CreatedTime: util.GetCurrentTime(),
+	}
+
+	affected, err := adapter.engine.Insert(&attempt)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// GetAuthFailures returns the number of failures within AuthFailureExpiredTime and the time of the last one.
+func GetAuthFailures(action, target string) (int, string) {
+	attempts := []*AuthAttempt{}
+	err := adapter.engine.Where("action = ?", action).And("target = ?", target).
+		And("created_time > ?", util.GetTimeHour(-AuthFailureExpiredTime)).Desc("created_time").Find(&attempts)
+	if err != nil {
+		panic(err)
+	}
+
+	if len(attempts) == 0 {
+		return 0, ""
+	}
+	return len(attempts), attempts[0].CreatedTime
+}
+
+// GetAuthLockTime returns the time until which the target is locked, or "" if it isn't.
+// The lock starts at maxFailures failures and doubles with every further failure, up to MaxAuthLockTime.
+func GetAuthLockTime(action, target string, maxFailures int) string {
+	num, lastTime := GetAuthFailures(action, target)
+	if num < maxFailures {
+		return ""
+	}
+
+	lockTime := MaxAuthLockTime
+	if num-maxFailures < 11 {
+		lockTime = 1 << uint(num-maxFailures)
+		if lockTime > MaxAuthLockTime {
+			lockTime = MaxAuthLockTime
+		}
+	}
+
+	unlockTime := util.GetTimeAfterMinute(lastTime, lockTime)
+	if unlockTime <= util.GetCurrentTime() {
+		return ""
+	}
+	return unlockTime
+}
+
+func ClearAuthFailures(action, target string) bool {
+	affected, err := adapter.engine.Where("action = ?", action).And("target = ?", target).Delete(&AuthAttempt{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// DeleteExpiredAuthAttempts deletes the failures before the date, return effects num.
+func DeleteExpiredAuthAttempts(date string) int {
+	affected, err := adapter.engine.Where("created_time < ?", date).Delete(&AuthAttempt{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(affected)
+}
@@ -0,0 +1,289 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+
+	"github.com/casbin/casnode/util"
+)
+
+// Badge is defined by admins. Badges without a rule are granted manually, the others are awarded
+// by the cron job to the members reaching Threshold of the rule.
+type Badge struct {
+	Id          string `xorm:"varchar(100) notnull pk" json:"id"`
+	Name        string `xorm:"varchar(100)" json:"name"`
+	Description string `xorm:"varchar(500)" json:"description"`
+	Icon        string `xorm:"varchar(200)" json:"icon"`
+	Rule        string `xorm:"varchar(40) index" json:"rule"`
+	Threshold   int    `xorm:"int" json:"threshold"`
+	CreatedTime string `xorm:"varchar(40)" json:"createdTime"`
+}
+
+// MemberBadge is a badge owned by the member, GrantedBy is empty if it's awarded by a rule.
+// Hidden badges aren't shown on the member's profile.
+type MemberBadge struct {
+	Id          int    `xorm:"int notnull pk autoincr" json:"id"`
+	MemberId    string `xorm:"varchar(100) unique(member_badge)" json:"memberId"`
+	BadgeId     string `xorm:"varchar(100) unique(member_badge) index" json:"badgeId"`
+	GrantedBy   string `xorm:"varchar(100)" json:"grantedBy"`
+	Hidden      bool   `xorm:"bool" json:"hidden"`
+	CreatedTime string `xorm:"varchar(40)" json:"createdTime"`
+}
+
+const (
+	BadgeRuleTopics          = "topics"
+	BadgeRuleThanksReceived  = "thanksReceived"
+	BadgeRuleMemberDays      = "memberDays"
+	BadgeRuleAcceptedAnswers = "acceptedAnswers"
+)
+
+// IsValidBadgeRule returns whether the rule is known, the empty rule means the badge is granted manually.
+func IsValidBadgeRule(rule string) bool {
+	switch rule {
+	case "", BadgeRuleTopics, BadgeRuleThanksReceived, BadgeRuleMemberDays, BadgeRuleAcceptedAnswers:
+		return true
+	}
+	return false
+}
+
+func GetBadges() []*Badge {
+	badges := []*Badge{}
+	err := adapter.engine.Asc("created_time").Find(&badges)
+	if err != nil {
+		panic(err)
+	}
+
+	return badges
+}
+
+func GetBadge(id string) *Badge {
+	badge := Badge{Id: id}
+	existed, err := adapter.engine.Get(&badge)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &badge
+	}
+	return nil
+}
+
+func HasBadge(id string) bool {
+	existed, err := adapter.engine.Id(id).Exist(&Badge{})
+	if err != nil {
+		panic(err)
+	}
+
+	return existed
+}
+
+func AddBadge(badge *Badge) bool {
+	affected, err := adapter.engine.Insert(badge)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func UpdateBadge(id string, badge *Badge) bool {
+	affected, err := adapter.engine.Id(id).Cols("name, description, icon, rule, threshold").Update(badge)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// DeleteBadge deletes the badge and takes it back from the members.
+func DeleteBadge(id string) bool {
+	affected, err := adapter.engine.Id(id).Delete(&Badge{})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = adapter.engine.Where("badge_id = ?", id).Delete(&MemberBadge{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// GetMemberBadges returns the badges of the member, the hidden ones are only included if includeHidden is true.
+func GetMemberBadges(memberId string, includeHidden bool) []*MemberBadgeResponse {
+	badges := []*MemberBadgeResponse{}
+	session := adapter.engine.Table("member_badge").Join("INNER", "badge", "badge.id = member_badge.badge_id").
+		Where("member_badge.member_id = ?", memberId)
+	if !includeHidden {
+		session = session.And("member_badge.hidden = ?", false)
+	}
+	err := session.Asc("member_badge.id").Cols("member_badge.*, badge.name, badge.description, badge.icon").Find(&badges)
+	if err != nil {
+		panic(err)
+	}
+
+	return badges
+}
+
+func HasMemberBadge(memberId, badgeId string) bool {
+	existed, err := adapter.engine.Where("member_id = ?", memberId).And("badge_id = ?", badgeId).Exist(&MemberBadge{})
+	if err != nil {
+		panic(err)
+	}
+
+	return existed
+}
+
+// GetMemberBadgeName returns the name of the badge by the id of the member's badge, used by notifications.
+func GetMemberBadgeName(id int) string {
+	var name string
+	_, err := adapter.engine.Table("member_badge").Join("INNER", "badge", "badge.id = member_badge.badge_id").
+		Where("member_badge.id = ?", id).Cols("badge.name").Get(&name)
+	if err != nil {
+		panic(err)
+	}
+
+	return name
+}
+
+// AwardBadge gives the badge to the member and notifies the member, grantedBy is empty for the rules.
+func AwardBadge(memberId, badgeId, grantedBy string) bool {
+	if HasMemberBadge(memberId, badgeId) {
+		return false
+	}
+
+	memberBadge := MemberBadge{
+		MemberId:    memberId,
+		BadgeId:     badgeId,
+		GrantedBy:   grantedBy,
+		CreatedTime: util.GetCurrentTime(),
+	}
+	affected, err := adapter.engine.Insert(&memberBadge)
+	if err != nil {
+		panic(err)
+	}
+	if affected == 0 {
+		return false
+	}
+
+	notification := Notification{
+		NotificationType: 7,
+		ObjectId:         memberBadge.Id,
+		CreatedTime:      util.GetCurrentTime(),
+		SenderId:         grantedBy,
+		ReceiverId:       memberId,
+		Status:           1,
+	}
+	_ = AddNotification(&notification)
+	name := GetMemberBadgeName(memberBadge.Id)
+	sendNotificationMail(memberId, notification.NotificationType, "You have got the badge: "+name, "You have got the badge: "+name+".", 0)
+	return true
+}
+
+func RevokeBadge(memberId, badgeId string) bool {
+	affected, err := adapter.engine.Where("member_id = ?", memberId).And("badge_id = ?", badgeId).Delete(&MemberBadge{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// UpdateMemberBadgeHidden shows or hides the badge on the member's profile.
+func UpdateMemberBadgeHidden(memberId, badgeId string, hidden bool) bool {
+	memberBadge := MemberBadge{Hidden: hidden}
+	affected, err := adapter.engine.Where("member_id = ?", memberId).And("badge_id = ?", badgeId).Cols("hidden").Update(&memberBadge)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// GetAcceptedAnswersNum returns how many replies of the member have been accepted as the answers of topics.
+func GetAcceptedAnswersNum(memberId string) int {
+	total, err := adapter.engine.Table("topic").Join("INNER", "reply", "reply.id = topic.accepted_reply_id").
+		Where("reply.author = ?", memberId).And("reply.deleted = ?", false).And("topic.deleted = ?", false).
+		Count()
+	if err != nil {
+		panic(err)
+	}
+
+	return int(total)
+}
+
+// getBadgeRuleMembers returns the members reaching the threshold of the rule.
+func getBadgeRuleMembers(rule string, threshold int) []string {
+	ids := []string{}
+	having := fmt.Sprintf("count(*) >= %d", threshold)
+
+	var err error
+	switch rule {
+	case BadgeRuleTopics:
+		err = adapter.engine.Table("topic").Where("deleted = ?", false).
+			GroupBy("author").Having(having).Cols("author").Find(&ids)
+	case BadgeRuleThanksReceived:
+		err = adapter.engine.Table("consumption_record").In("consumption_type", 2, 3).
+			GroupBy("receiver_id").Having(having).Cols("receiver_id").Find(&ids)
+	case BadgeRuleMemberDays:
+		err = adapter.engine.Table("member").Where("created_time <= ?", util.GetTimeDay(-threshold)).
+			Cols("id").Find(&ids)
+	case BadgeRuleAcceptedAnswers:
+		err = adapter.engine.Table("topic").Join("INNER", "reply", "reply.id = topic.accepted_reply_id").
+			Where("reply.deleted = ?", false).And("topic.deleted = ?", false).
+			GroupBy("reply.author").Having(having).Cols("reply.author").Find(&ids)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	return ids
+}
+
+// AwardBadges awards the badges with rules to the members who have earned them, returns the number of awarded badges.
+func AwardBadges() int {
+	badges := []*Badge{}
+	err := adapter.engine.Where("rule != ?", "").Find(&badges)
+	if err != nil {
+		panic(err)
+	}
+
+	num := 0
+	for _, badge := range badges {
+		owners := map[string]bool{}
+		ids := []string{}
+		err = adapter.engine.Table("member_badge").Where("badge_id = ?", badge.Id).Cols("member_id").Find(&ids)
+		if err != nil {
+			panic(err)
+		}
+		for _, id := range ids {
+			owners[id] = true
+		}
+
+		for _, id := range getBadgeRuleMembers(badge.Rule, badge.Threshold) {
+			if owners[id] || id == DeletedMemberId {
+				continue
+			}
+			if AwardBadge(id, badge.Id, "") {
+				num++
+			}
+		}
+	}
+
+	return num
+}
@@ -23,6 +23,7 @@ import (
 // ConsumptionType 1-9 means:
 // login bonus, receive thanks(topic), receive thanks(reply), thanks(topic)
 // thanks(reply), new reply, receive reply bonus, new topic, top topic.
+// 10 means the object has been deleted (only in responses), 11 means new invite code.
 type ConsumptionRecord struct {
 	Id              int    `xorm:"int notnull pk autoincr" json:"id"`
 	Amount          int    `xorm:"int" json:"amount"`
@@ -231,7 +232,7 @@ func GetThanksStatus(memberId string, id, recordType int) bool {
 	return total != 0
 }
 
-func CreateTopicConsumption(consumerId string, id int) bool {
+func CreateTopicConsumption(consumerId string, id int, cost int) bool {
 	record := ConsumptionRecord{
 		//Id:              util.IntToString(GetConsumptionRecordId()),
 		ReceiverId:      consumerId,
@@ -239,7 +240,7 @@ func CreateTopicConsumption(consumerId string, id int) bool {
 		CreatedTime:     util.GetCurrentTime(),
 		ConsumptionType: 8,
 	}
-	record.Amount = CreateTopicCost
+	record.Amount = cost
 	record.Amount = -record.Amount
 	balance := GetMemberBalance(consumerId)
 	if balance+record.Amount < 0 {
@@ -253,7 +254,7 @@ func CreateTopicConsumption(consumerId string, id int) bool {
 	return true
 }
 
-func CreateReplyConsumption(consumerId string, id int) bool {
+func CreateReplyConsumption(consumerId string, id int, cost int) bool {
 	record := ConsumptionRecord{
 		//Id:              util.IntToString(GetConsumptionRecordId()),
 		ReceiverId:      consumerId,
@@ -261,7 +262,7 @@ func CreateReplyConsumption(consumerId string, id int) bool {
 		CreatedTime:     util.GetCurrentTime(),
 		ConsumptionType: 6,
 	}
-	record.Amount = CreateReplyCost
+	record.Amount = cost
 	record.Amount = -record.Amount
 	balance := GetMemberBalance(consumerId)
 	if balance+record.Amount < 0 {
@@ -275,6 +276,27 @@ func CreateReplyConsumption(consumerId string, id int) bool {
 	return true
 }
 
+// CreateInviteCodeConsumption charges the member for the invite code.
+func CreateInviteCodeConsumption(consumerId string, id int, cost int) bool {
+	record := ConsumptionRecord{
+		ReceiverId:      consumerId,
+		ObjectId:        id,
+		CreatedTime:     util.GetCurrentTime(),
+		ConsumptionType: 11,
+	}
+	record.Amount = -cost
+	balance := GetMemberBalance(consumerId)
+	if balance+record.Amount < 0 {
+		return false
+	}
+
+	record.Balance = balance + record.Amount
+	AddBalance(&record)
+	UpdateMemberBalances(consumerId, record.Amount)
+
+	return true
+}
+
 func GetReplyBonus(author, consumerId string, id int) {
 	if author == consumerId {
 		return
@@ -108,6 +108,39 @@ func UpdateHighestOnlineNum(num int) bool {
 	return affected != 0
 }
 
+// getBasicInfoValue returns the value of the setting, or defaultValue if it's not set.
+func getBasicInfoValue(id, defaultValue string) string {
+	info := BasicInfo{Id: id}
+	existed, err := adapter.engine.Get(&info)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return info.Value
+	}
+	return defaultValue
+}
+
+func setBasicInfoValue(id, value string) bool {
+	info := BasicInfo{Id: id, Value: value}
+	existed, err := adapter.engine.Exist(&BasicInfo{Id: id})
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		_, err = adapter.engine.Id(id).Cols("value").Update(&info)
+	} else {
+		_, err = adapter.engine.Insert(&info)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	return true
+}
+
 func GetCaptcha() (string, []byte) {
 	id := captcha.NewLen(5)
 
@@ -140,6 +173,17 @@ func GetCronJobs() []*CronJob {
 		if err != nil {
 			panic(err)
 		}
+
+		// the jobs added in newer versions are missing in the saved settings
+		existed := map[string]bool{}
+		for _, job := range jobs {
+			existed[job.Id] = true
+		}
+		for _, job := range DefaultCronJobs {
+			if !existed[job.Id] {
+				jobs = append(jobs, job)
+			}
+		}
 		return jobs
 	} else {
 		jobs, err := json.Marshal(DefaultCronJobs)
@@ -173,6 +217,17 @@ func GetCronUpdateJobs() []*UpdateJob {
 		if err != nil {
 			panic(err)
 		}
+
+		// the updates added in newer versions are missing in the saved settings
+		existed := map[string]bool{}
+		for _, post := range posts {
+			existed[post.Id] = true
+		}
+		for _, post := range DefaultCronUpdates {
+			if !existed[post.Id] {
+				posts = append(posts, post)
+			}
+		}
 		return posts
 	} else {
 		posts, err := json.Marshal(DefaultCronUpdates)
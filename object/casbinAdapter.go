@@ -0,0 +1,124 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	"xorm.io/xorm"
+)
+
+// CasbinRule is a policy line of the permission model: "p" lines grant a permission to a role,
+// "g" lines assign a role to a member in a domain.
+type CasbinRule struct {
+	Id    int    `xorm:"int notnull pk autoincr" json:"id"`
+	Ptype string `xorm:"varchar(100) index" json:"ptype"`
+	V0    string `xorm:"varchar(100) index" json:"v0"`
+	V1    string `xorm:"varchar(100)" json:"v1"`
+	V2    string `xorm:"varchar(100)" json:"v2"`
+	V3    string `xorm:"varchar(100)" json:"v3"`
+	V4    string `xorm:"varchar(100)" json:"v4"`
+	V5    string `xorm:"varchar(100)" json:"v5"`
+}
+
+// casbinAdapter stores the policy of the enforcer in the casbin_rule table.
+type casbinAdapter struct {
+	engine *xorm.Engine
+}
+
+func newCasbinRule(ptype string, rule []string) *CasbinRule {
+	line := CasbinRule{Ptype: ptype}
+	values := []*string{&line.V0, &line.V1, &line.V2, &line.V3, &line.V4, &line.V5}
+	for i := 0; i < len(rule) && i < len(values); i++ {
+		*values[i] = rule[i]
+	}
+
+	return &line
+}
+
+func (line *CasbinRule) toArray() []string {
+	res := []string{line.Ptype}
+	for _, v := range []string{line.V0, line.V1, line.V2, line.V3, line.V4, line.V5} {
+		if v == "" {
+			break
+		}
+		res = append(res, v)
+	}
+
+	return res
+}
+
+func (a *casbinAdapter) LoadPolicy(model model.Model) error {
+	lines := []*CasbinRule{}
+	err := a.engine.Asc("id").Find(&lines)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		err = persist.LoadPolicyArray(line.toArray(), model)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *casbinAdapter) SavePolicy(model model.Model) error {
+	_, err := a.engine.Transaction(func(session *xorm.Session) (interface{}, error) {
+		_, err := session.Where("1 = 1").Delete(&CasbinRule{})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sec := range []string{"p", "g"} {
+			for ptype, ast := range model[sec] {
+				for _, rule := range ast.Policy {
+					_, err = session.Insert(newCasbinRule(ptype, rule))
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+		return nil, nil
+	})
+
+	return err
+}
+
+func (a *casbinAdapter) AddPolicy(sec string, ptype string, rule []string) error {
+	_, err := a.engine.Insert(newCasbinRule(ptype, rule))
+	return err
+}
+
+func (a *casbinAdapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	_, err := a.engine.Delete(newCasbinRule(ptype, rule))
+	return err
+}
+
+func (a *casbinAdapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	session := a.engine.Where("ptype = ?", ptype)
+	columns := []string{"v0", "v1", "v2", "v3", "v4", "v5"}
+	for i, v := range fieldValues {
+		if v != "" && fieldIndex+i < len(columns) {
+			session = session.And(columns[fieldIndex+i]+" = ?", v)
+		}
+	}
+
+	_, err := session.Delete(&CasbinRule{})
+	return err
+}
@@ -78,21 +78,11 @@ func CheckMemberSignupWithPhone(member string, phoneNumber string) string {
 	}
 }
 
-func CheckMemberSignupWithQQ(member string, qqOpenId string) string {
-	if len(member) == 0 || len(qqOpenId) == 0 {
-		return "Username empty or qq id empty"
-	} else if HasQQAccount(qqOpenId) != "" {
-		return "This qq account has already been linked with another account"
-	} else {
-		return ""
-	}
-}
-
-func CheckMemberSignupWithWeChat(member string, wechatOpenId string) string {
-	if len(member) == 0 || len(wechatOpenId) == 0 {
-		return "Username empty or WeChat id empty"
-	} else if HasWeChatAccount(wechatOpenId) != "" {
-		return "This wechat account has already been linked with another account"
+func CheckMemberSignupWithLinkedAccount(member string, provider string, subject string) string {
+	if len(member) == 0 || len(subject) == 0 {
+		return "Username empty or " + provider + " id empty"
+	} else if GetLinkedMember(provider, subject) != "" {
+		return "This " + provider + " account has already been linked with another account"
 	} else {
 		return ""
 	}
@@ -114,38 +104,6 @@ func HasPhone(phoneNumber string) string {
 	return ""
 }
 
-func HasGithubAccount(githubAccount string) string {
-	userInfo := GetGithubAccount(githubAccount)
-	if userInfo != nil {
-		return userInfo.Id
-	}
-	return ""
-}
-
-func HasGoogleAccount(googleAccount string) string {
-	userInfo := GetGoogleAccount(googleAccount)
-	if userInfo != nil {
-		return userInfo.Id
-	}
-	return ""
-}
-
-func HasQQAccount(qqOpenId string) string {
-	userInfo := GetQQAccount(qqOpenId)
-	if userInfo != nil {
-		return userInfo.Id
-	}
-	return ""
-}
-
-func HasWeChatAccount(wechatOpenId string) string {
-	userInfo := GetWechatAccount(wechatOpenId)
-	if userInfo != nil {
-		return userInfo.Id
-	}
-	return ""
-}
-
 func HasNode(id string) bool {
 	node := GetNode(id)
 
@@ -164,20 +122,6 @@ func HasPlane(id string) bool {
 	return plane != nil
 }
 
-// IsMuted check member whether is muted.
-func IsMuted(id string) bool {
-	status := GetMemberStatus(id)
-
-	return status == 2
-}
-
-// IsForbidden check member whether is forbidden.
-func IsForbidden(id string) bool {
-	status := GetMemberStatus(id)
-
-	return status == 3
-}
-
 func filterUnsafeHTML(content string) string {
 	if content == "" {
 		return content
@@ -41,11 +41,50 @@ var (
 	TopicHitRecordExpiredTime  = 1    // day
 	ValidateCodeExpiredTime    = 20   // minutes
 	DefaultTopTopicTime        = 10   // minutes
+	TwoFactorPendingTime       = 5    // minutes
 	OnlineMemberExpiedTime     = 10   // minutes
+	MaxTwoFactorAttempts       = 5
+	MaxAccessTokenNum          = 20
+	MaxAccessTokenExpireDays   = 365
+	SigninCaptchaFailures      = 3
+	SigninLockFailures         = 5
+	SigninIpLockFailures       = 20
+	MaxValidateCodeAttempts    = 5
+	ValidateCodeDestQuota      = 10   // per day
+	ValidateCodeIpQuota        = 30   // per day
+	ValidateCodeSendInterval   = 1    // minute
+	MaxAuthLockTime            = 1440 // minutes
+	AuthFailureExpiredTime     = 24   // hours
+	UsernameChangeInterval     = 30   // days
+	MaxInviteCodeNum           = 5
+	InviteCodeCost             = 100
+	InviteCodeExpiredTime      = 30 // days
+	ReauthExpiredTime          = 10 // minutes
+	PostLinkTrustLevel         = 1
+	UploadFileTrustLevel       = 1
+	MaxMentionNums             = []int{2, 5, 10, 20} // per topic or reply, by trust level
 	UseOAuthProxy              = false
 	DefaultUploadFileQuota     = 50
+	MailBatchNum               = 20
+	MailMaxAttempts            = 6
+	DigestItemNum              = 20                 // per section
+	MailQueueInterval          = 10                 // seconds
+	EventHeartbeatInterval     = 30                 // seconds
+	EventBufferNum             = 16                 // per subscriber
+	MailRetryInterval          = 1                  // minutes, doubled after each failed attempt
+	MailSendingTimeout         = 10                 // minutes
+	MailExpiredTime            = 7                  // days
+	MaxAvatarFileSize          = 2                  // MB
+	AvatarSizes                = []int{256, 73, 48} // pixels, the largest one is the avatar of the member
 	Domain                     = "forum.casbin.com" // domain
 
+	// TrustLevelRequirements[i] is the activity needed for trust level i.
+	TrustLevelRequirements = []TrustLevelRequirement{
+		{},
+		{Days: 1, Replies: 1},
+		{Days: 15, Topics: 3, Replies: 20, ThanksReceived: 5},
+		{Days: 60, Topics: 10, Replies: 100, ThanksReceived: 20},
+	}
 	DefaultCronJobs = []*CronJob{
 		{
 			Id:       "updateExpiredData",
@@ -84,10 +123,35 @@ var (
 			JobId: "expireData",
 			State: "active",
 		},
+		{
+			Id:    "liftSuspensions",
+			JobId: "expireData",
+			State: "active",
+		},
 		{
 			Id:    "expireOnlineMember",
 			JobId: "expireData",
 			State: "active",
 		},
+		{
+			Id:    "updateTrustLevels",
+			JobId: "updateExpiredData",
+			State: "active",
+		},
+		{
+			Id:    "awardBadges",
+			JobId: "updateExpiredData",
+			State: "active",
+		},
+		{
+			Id:    "sendDigests",
+			JobId: "updateExpiredData",
+			State: "active",
+		},
+		{
+			Id:    "expireMails",
+			JobId: "updateExpiredData",
+			State: "active",
+		},
 	}
 )
@@ -65,8 +65,19 @@ func (job *UpdateJob) updateInfo() (bool, int) {
 		expiredValidateCodeDate := util.GetTimeMinute(-ValidateCodeExpiredTime)
 
 		num = ExpireValidateCode(expiredValidateCodeDate)
+		num += DeleteExpiredAuthAttempts(util.GetTimeHour(-AuthFailureExpiredTime))
 	case "expireTopTopic":
 		num = ExpireTopTopic()
+	case "liftSuspensions":
+		num = LiftExpiredSuspensions()
+	case "updateTrustLevels":
+		num = UpdateTrustLevels()
+	case "awardBadges":
+		num = AwardBadges()
+	case "sendDigests":
+		num = SendDigests()
+	case "expireMails":
+		num = DeleteExpiredMails(util.GetTimeDay(-MailExpiredTime))
 	case "expireOnlineMember":
 		expiredActiveDate := util.GetTimeMinute(-OnlineMemberExpiedTime)
 
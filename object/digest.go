@@ -0,0 +1,264 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"fmt"
+	htmlTemplate "html/template"
+	textTemplate "text/template"
+
+	"github.com/casbin/casnode/service"
+	"github.com/casbin/casnode/util"
+)
+
+// DigestIntervals are the days between two digests of the Member.DigestFrequency values.
+var DigestIntervals = map[string]int{
+	"daily":  1,
+	"weekly": 7,
+}
+
+// DigestItem is a line of the digest email.
+type DigestItem struct {
+	Title  string
+	Author string
+	Url    string
+}
+
+// Digest is the forum activity of a member since the last digest.
+type Digest struct {
+	Notifications []*DigestItem // unread, of the categories with the digest channel turned on
+	HotTopics     []*DigestItem // in the favorite nodes
+	Replies       []*DigestItem // to the watched topics
+}
+
+func (d *Digest) IsEmpty() bool {
+	return len(d.Notifications) == 0 && len(d.HotTopics) == 0 && len(d.Replies) == 0
+}
+
+var digestTexts = map[string]map[string]string{
+	"en": {
+		"subject":       "Your %s digest",
+		"daily":         "daily",
+		"weekly":        "weekly",
+		"notifications": "Unread notifications",
+		"hotTopics":     "Hot topics in your favorite nodes",
+		"replies":       "New replies to the topics you watch",
+		"by":            "by",
+		"unsubscribe":   "Change the digest in your settings",
+	},
+	"zh": {
+		"subject":       "你的%s摘要",
+		"daily":         "每日",
+		"weekly":        "每周",
+		"notifications": "未读提醒",
+		"hotTopics":     "收藏节点中的热门主题",
+		"replies":       "关注的主题的新回复",
+		"by":            "来自",
+		"unsubscribe":   "在设置中修改摘要",
+	},
+}
+
+const digestHtml = `{{define "section"}}{{if .Items}}<h3>{{.Name}}</h3>
+<ul>{{range .Items}}
+<li><a href="{{.Url}}">{{.Title}}</a>{{if .Author}} {{$.By}} {{.Author}}{{end}}</li>{{end}}
+</ul>
+{{end}}{{end}}{{template "section" .Notifications}}{{template "section" .HotTopics}}{{template "section" .Replies}}
+<p style="font-size:small;color:#666;"><a href="{{.SettingsUrl}}">{{.Unsubscribe}}</a></p>`
+
+const digestText = `{{define "section"}}{{if .Items}}{{.Name}}
+{{range .Items}}- {{.Title}}{{if .Author}} {{$.By}} {{.Author}}{{end}}
+  {{.Url}}
+{{end}}
+{{end}}{{end}}{{template "section" .Notifications}}{{template "section" .HotTopics}}{{template "section" .Replies}}{{.Unsubscribe}}: {{.SettingsUrl}}
+`
+
+var digestHtmlTemplate = htmlTemplate.Must(htmlTemplate.New("digest").Parse(digestHtml))
+var digestTextTemplate = textTemplate.Must(textTemplate.New("digest").Parse(digestText))
+
+type digestSection struct {
+	Name  string
+	By    string
+	Items []*DigestItem
+}
+
+func getTopicUrl(topicId int) string {
+	return fmt.Sprintf("https://%s/t/%d", Domain, topicId)
+}
+
+// GetMemberDigest collects the activity of the member since the time.
+func GetMemberDigest(memberId, since string) *Digest {
+	digest := Digest{}
+
+	// replies are collected from the watched topics below, the other types are filtered before the limit
+	notificationTypes := []int{}
+	for notificationType := 2; notificationType <= 10; notificationType++ {
+		if IsNotificationEnabled(memberId, notificationType, NotificationChannelDigest) {
+			notificationTypes = append(notificationTypes, notificationType)
+		}
+	}
+
+	notifications := []*NotificationResponse{}
+	if len(notificationTypes) != 0 {
+		err := adapter.engine.Table("notification").Where("receiver_id = ?", memberId).In("status", 1, 4).
+			In("notification_type", notificationTypes).And("created_time > ?", since).
+			Desc("created_time").Limit(DigestItemNum).Find(&notifications)
+		if err != nil {
+			panic(err)
+		}
+	}
+	for _, notification := range notifications {
+		fillNotification(notification)
+		url := fmt.Sprintf("https://%s/notifications", Domain)
+		if notification.NotificationType <= 5 {
+			url = getTopicUrl(notification.ObjectId)
+		}
+		digest.Notifications = append(digest.Notifications, &DigestItem{Title: notification.Title, Author: notification.SenderId, Url: url})
+	}
+
+	nodeIds := []string{}
+	err := adapter.engine.Table("favorites").Where("member_id = ?", memberId).And("favorites_type = ?", 3).Cols("object_id").Find(&nodeIds)
+	if err != nil {
+		panic(err)
+	}
+	if len(nodeIds) != 0 {
+		topics := []*Topic{}
+		err = excludeBlockedAuthors(adapter.engine.Table("topic"), memberId).In("node_id", nodeIds).And("deleted = ?", false).
+			And("(created_time > ? or last_reply_time > ?)", since, since).And("author != ?", memberId).
+			Desc("hot").Limit(DigestItemNum).Omit("content").Find(&topics)
+		if err != nil {
+			panic(err)
+		}
+		for _, topic := range topics {
+			digest.HotTopics = append(digest.HotTopics, &DigestItem{Title: topic.Title, Author: topic.Author, Url: getTopicUrl(topic.Id)})
+		}
+	}
+
+	// replies are notified as reply notifications too, so they are only collected here
+	if IsNotificationEnabled(memberId, 1, NotificationChannelDigest) {
+		replies := []*LatestReply{}
+		err = adapter.engine.Table("reply").Join("INNER", "topic", "topic.id = reply.topic_id").
+			Join("LEFT OUTER", "topic_watch", "topic_watch.topic_id = reply.topic_id and topic_watch.member_id = ?", memberId).
+			Where("reply.deleted = ?", false).And("reply.created_time > ?", since).And("reply.author != ?", memberId).
+			And("(topic_watch.watch_level = ? or (topic_watch.id is null and topic.author = ?))", 1, memberId).
+			Desc("reply.created_time").Limit(DigestItemNum).
+			Cols("reply.author, reply.created_time, topic.id, topic.title").Find(&replies)
+		if err != nil {
+			panic(err)
+		}
+		blocked := map[string]bool{}
+		for _, id := range GetBlockedMemberIds(memberId) {
+			blocked[id] = true
+		}
+		for _, reply := range replies {
+			if !blocked[reply.Author] {
+				digest.Replies = append(digest.Replies, &DigestItem{Title: reply.TopicTitle, Author: reply.Author, Url: getTopicUrl(reply.TopicId)})
+			}
+		}
+	}
+
+	return &digest
+}
+
+// RenderDigest returns the subject, the HTML and the text of the digest email in the language.
+func RenderDigest(digest *Digest, frequency, language string) (string, string, string) {
+	texts, ok := digestTexts[language]
+	if !ok {
+		texts = digestTexts["en"]
+	}
+
+	data := struct {
+		Notifications, HotTopics, Replies digestSection
+		Unsubscribe, SettingsUrl          string
+	}{
+		Notifications: digestSection{Name: texts["notifications"], By: texts["by"], Items: digest.Notifications},
+		HotTopics:     digestSection{Name: texts["hotTopics"], By: texts["by"], Items: digest.HotTopics},
+		Replies:       digestSection{Name: texts["replies"], By: texts["by"], Items: digest.Replies},
+		Unsubscribe:   texts["unsubscribe"],
+		SettingsUrl:   fmt.Sprintf("https://%s/settings/forum", Domain),
+	}
+
+	html := bytes.Buffer{}
+	err := digestHtmlTemplate.Execute(&html, data)
+	if err != nil {
+		panic(err)
+	}
+	text := bytes.Buffer{}
+	err = digestTextTemplate.Execute(&text, data)
+	if err != nil {
+		panic(err)
+	}
+
+	return fmt.Sprintf(texts["subject"], texts[frequency]), html.String(), text.String()
+}
+
+func updateMemberDigestSentTime(id, sentTime string) {
+	member := Member{DigestSentTime: sentTime}
+	_, err := adapter.engine.Id(id).Cols("digest_sent_time").Update(&member)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// SendDigests queues the digests which are due, returns the number of queued emails. The activity since
+// the last digest is collected, or since one interval ago for the first digest.
+func SendDigests() int {
+	members := []*Member{}
+	err := adapter.engine.Where("digest_frequency != ?", "").And("email != ?", "").And("status in (?, ?)", 1, 2).
+		Cols("id, email, language, digest_frequency, digest_sent_time").Find(&members)
+	if err != nil {
+		panic(err)
+	}
+
+	num := 0
+	for _, member := range members {
+		days, ok := DigestIntervals[member.DigestFrequency]
+		if !ok {
+			continue
+		}
+		// an hour earlier, so that the digest isn't put off by the time of the last run of the cron job
+		if member.DigestSentTime != "" && member.DigestSentTime > util.GetTimeHour(-days*24+1) {
+			continue
+		}
+
+		since := member.DigestSentTime
+		if since == "" {
+			since = util.GetTimeDay(-days)
+		}
+		sentTime := util.GetCurrentTime()
+
+		digest := GetMemberDigest(member.Id, since)
+		if !digest.IsEmpty() {
+			subject, html, text := RenderDigest(digest, member.DigestFrequency, member.Language)
+			if EnqueueMail(service.NewDigestMail(subject, html, text, member.Email)) {
+				num++
+			}
+		}
+		updateMemberDigestSentTime(member.Id, sentTime)
+	}
+
+	return num
+}
+
+// UpdateMemberDigestFrequency turns on the daily or weekly digest, or turns it off with the empty frequency.
+func UpdateMemberDigestFrequency(id, frequency string) bool {
+	member := Member{DigestFrequency: frequency}
+	affected, err := adapter.engine.Id(id).MustCols("digest_frequency").Update(&member)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
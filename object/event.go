@@ -0,0 +1,162 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"sync"
+)
+
+// Event types pushed to the clients.
+const (
+	EventNotification = "notification" // a new notification of the member
+	EventUnreadNum    = "unreadNum"    // the number of the member's unread notifications
+	EventReply        = "reply"        // a new reply of the topic being viewed
+)
+
+// Event is pushed to the subscribers. Id is the notification id of the notification events, clients
+// reconnect with the last one to catch up. SenderId is the member causing the event, if any.
+type Event struct {
+	Id       int
+	Type     string
+	SenderId string
+	Data     interface{}
+}
+
+// ReplyEvent is the data of the reply events, clients load the reply by themselves.
+type ReplyEvent struct {
+	Id          int    `json:"id"`
+	TopicId     int    `json:"topicId"`
+	Author      string `json:"author"`
+	CreatedTime string `json:"createdTime"`
+}
+
+// EventSubscriber receives the events of the member, and of the topic if TopicId isn't 0.
+// Events are dropped if the subscriber falls behind by more than EventBufferNum.
+type EventSubscriber struct {
+	MemberId string
+	TopicId  int
+	Events   chan *Event
+}
+
+// eventBroker is the in-process pub/sub of the events, so only the clients connected to
+// the same server instance receive them.
+type eventBroker struct {
+	sync.RWMutex
+	members map[string]map[*EventSubscriber]bool
+	topics  map[int]map[*EventSubscriber]bool
+}
+
+var broker = eventBroker{
+	members: map[string]map[*EventSubscriber]bool{},
+	topics:  map[int]map[*EventSubscriber]bool{},
+}
+
+func SubscribeEvents(memberId string, topicId int) *EventSubscriber {
+	subscriber := EventSubscriber{
+		MemberId: memberId,
+		TopicId:  topicId,
+		Events:   make(chan *Event, EventBufferNum),
+	}
+
+	broker.Lock()
+	defer broker.Unlock()
+	if broker.members[memberId] == nil {
+		broker.members[memberId] = map[*EventSubscriber]bool{}
+	}
+	broker.members[memberId][&subscriber] = true
+	if topicId != 0 {
+		if broker.topics[topicId] == nil {
+			broker.topics[topicId] = map[*EventSubscriber]bool{}
+		}
+		broker.topics[topicId][&subscriber] = true
+	}
+
+	return &subscriber
+}
+
+func UnsubscribeEvents(subscriber *EventSubscriber) {
+	broker.Lock()
+	defer broker.Unlock()
+	delete(broker.members[subscriber.MemberId], subscriber)
+	if len(broker.members[subscriber.MemberId]) == 0 {
+		delete(broker.members, subscriber.MemberId)
+	}
+	if subscriber.TopicId != 0 {
+		delete(broker.topics[subscriber.TopicId], subscriber)
+		if len(broker.topics[subscriber.TopicId]) == 0 {
+			delete(broker.topics, subscriber.TopicId)
+		}
+	}
+}
+
+func hasMemberSubscribers(memberId string) bool {
+	broker.RLock()
+	defer broker.RUnlock()
+	return len(broker.members[memberId]) != 0
+}
+
+func publish(subscribers map[*EventSubscriber]bool, event *Event) {
+	for subscriber := range subscribers {
+		select {
+		case subscriber.Events <- event:
+		default:
+		}
+	}
+}
+
+func publishMemberEvent(memberId string, event *Event) {
+	broker.RLock()
+	defer broker.RUnlock()
+	publish(broker.members[memberId], event)
+}
+
+func publishTopicEvent(topicId int, event *Event) {
+	broker.RLock()
+	defer broker.RUnlock()
+	publish(broker.topics[topicId], event)
+}
+
+// publishNotification pushes the new notification and the unread number to the receiver.
+func publishNotification(notification *Notification) {
+	if !hasMemberSubscribers(notification.ReceiverId) {
+		return
+	}
+
+	// fillNotification changes ObjectId, which the caller may still use
+	copied := *notification
+	response := NotificationResponse{Notification: &copied, Avatar: GetMemberAvatar(notification.SenderId)}
+	fillNotification(&response)
+
+	publishMemberEvent(notification.ReceiverId, &Event{Id: notification.Id, Type: EventNotification, SenderId: notification.SenderId, Data: &response})
+	publishUnreadNum(notification.ReceiverId)
+}
+
+func publishUnreadNum(memberId string) {
+	if !hasMemberSubscribers(memberId) {
+		return
+	}
+
+	publishMemberEvent(memberId, &Event{Type: EventUnreadNum, Data: GetUnreadNotificationNum(memberId)})
+}
+
+func publishReply(reply *Reply) {
+	event := ReplyEvent{
+		Id:          reply.Id,
+		TopicId:     reply.TopicId,
+		Author:      reply.Author,
+		CreatedTime: reply.CreatedTime,
+	}
+	publishTopicEvent(reply.TopicId, &Event{Type: EventReply, SenderId: reply.Author, Data: &event})
+}
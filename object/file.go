@@ -87,7 +87,7 @@ func DeleteFileRecord(id int) bool {
 }
 
 func FileEditable(memberId, author string) bool {
-	if CheckModIdentity(memberId) {
+	if CheckPermission(memberId, DomainSite, PermissionModerate) {
 		return true
 	}
 
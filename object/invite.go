@@ -0,0 +1,178 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/casbin/casnode/util"
+)
+
+// Signup modes: everyone could sign up, only with an invite code, or the new members need the approval of admin.
+const (
+	SignupModeOpen     = "open"
+	SignupModeInvite   = "invite"
+	SignupModeApproval = "approval"
+)
+
+// InviteCode is created by a member for someone to sign up in the invite-only mode, it could be used once.
+type InviteCode struct {
+	Id          int    `xorm:"int notnull pk autoincr" json:"id"`
+	Code        string `xorm:"varchar(100) unique" json:"code"`
+	CreatorId   string `xorm:"varchar(100) index" json:"creatorId"`
+	CreatedTime string `xorm:"varchar(40)" json:"createdTime"`
+	ExpireTime  string `xorm:"varchar(40)" json:"expireTime"`
+	UsedBy      string `xorm:"varchar(100)" json:"usedBy"`
+	UsedTime    string `xorm:"varchar(40)" json:"usedTime"`
+}
+
+func IsValidSignupMode(mode string) bool {
+	return mode == SignupModeOpen || mode == SignupModeInvite || mode == SignupModeApproval
+}
+
+func GetSignupMode() string {
+	return getBasicInfoValue("SignupMode", SignupModeOpen)
+}
+
+func UpdateSignupMode(mode string) bool {
+	return setBasicInfoValue("SignupMode", mode)
+}
+
+// GetMemberActiveInviteCodeNum returns the number of the member's codes that are neither used nor expired.
+func GetMemberActiveInviteCodeNum(memberId string) int {
+	total, err := adapter.engine.Where("creator_id = ?", memberId).And("used_by = ?", "").
+		And("expire_time > ?", util.GetCurrentTime()).Count(&InviteCode{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(total)
+}
+
+// AddInviteCode creates a code for the member, paid with cost coins.
+func AddInviteCode(creatorId string, cost int) (*InviteCode, string) {
+	if GetMemberActiveInviteCodeNum(creatorId) >= MaxInviteCodeNum {
+		return nil, "You have too many unused invite codes"
+	}
+
+	inviteCode := InviteCode{
+		Code:        hex.EncodeToString(util.GetRandomBytes(8)),
+		CreatorId:   creatorId,
+		CreatedTime: util.GetCurrentTime(),
+		ExpireTime:  util.GetTimeDay(InviteCodeExpiredTime),
+	}
+	_, err := adapter.engine.Insert(&inviteCode)
+	if err != nil {
+		panic(err)
+	}
+
+	if cost != 0 && !CreateInviteCodeConsumption(creatorId, inviteCode.Id, cost) {
+		_, err = adapter.engine.Id(inviteCode.Id).Delete(&InviteCode{})
+		if err != nil {
+			panic(err)
+		}
+		return nil, "You don't have enough balance."
+	}
+
+	return &inviteCode, ""
+}
+
+func GetMemberInviteCodes(memberId string) []*InviteCode {
+	codes := []*InviteCode{}
+	err := adapter.engine.Where("creator_id = ?", memberId).Desc("id").Find(&codes)
+	if err != nil {
+		panic(err)
+	}
+
+	return codes
+}
+
+// CheckInviteCode returns whether the code could be used to sign up.
+func CheckInviteCode(code string) bool {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return false
+	}
+
+	existed, err := adapter.engine.Where("code = ?", code).And("used_by = ?", "").
+		And("expire_time > ?", util.GetCurrentTime()).Exist(&InviteCode{})
+	if err != nil {
+		panic(err)
+	}
+
+	return existed
+}
+
+// UseInviteCode consumes the code for the new member, only one member could use it.
+func UseInviteCode(code, memberId string) bool {
+	inviteCode := InviteCode{UsedBy: memberId, UsedTime: util.GetCurrentTime()}
+	affected, err := adapter.engine.Where("code = ?", strings.TrimSpace(code)).And("used_by = ?", "").
+		And("expire_time > ?", util.GetCurrentTime()).Cols("used_by, used_time").Update(&inviteCode)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// DeleteInviteCode deletes an unused code of the member, the coins are not refunded.
+func DeleteInviteCode(creatorId string, id int) bool {
+	affected, err := adapter.engine.Id(id).Where("creator_id = ?", creatorId).And("used_by = ?", "").Delete(&InviteCode{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// IsPendingApproval returns whether the member signed up in the approval mode and hasn't been approved.
+func IsPendingApproval(id string) bool {
+	return GetMemberStatus(id) == 4
+}
+
+func GetPendingMembers() []*Member {
+	members := []*Member{}
+	err := adapter.engine.Where("status = ?", 4).Asc("created_time").Find(&members)
+	if err != nil {
+		panic(err)
+	}
+
+	return members
+}
+
+// HandlePendingMember approves the pending member, or forbids the member from logging in.
+func HandlePendingMember(id string, approved bool) bool {
+	member := Member{Status: 1}
+	if !approved {
+		member.Status = 3
+	}
+
+	affected, err := adapter.engine.Id(id).Where("status = ?", 4).Cols("status").Update(&member)
+	if err != nil {
+		panic(err)
+	}
+
+	if affected != 0 && !approved {
+		suspension := Suspension{
+			MemberId:    id,
+			Type:        SuspensionForbid,
+			Reason:      "The signup is not approved.",
+			CreatedTime: util.GetCurrentTime(),
+		}
+		AddSuspension(&suspension)
+	}
+	return affected != 0
+}
@@ -0,0 +1,246 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"errors"
+	"strings"
+
+	"xorm.io/xorm"
+)
+
+// LinkedAccount is an identity from an external provider linked with a member.
+// Subject is the provider's stable id of the user: the sub for google and oidc providers, the numeric id
+// for github, the open id for qq and wechat. Legacy identities were migrated from the member table with
+// the google email or the github login as Subject, it's rewritten to the stable id on the next sign in.
+type LinkedAccount struct {
+	Provider    string `xorm:"varchar(100) notnull pk" json:"provider"`
+	Subject     string `xorm:"varchar(100) notnull pk" json:"-"`
+	MemberId    string `xorm:"varchar(100) index" json:"memberId"`
+	Username    string `xorm:"varchar(100)" json:"username"`
+	Email       string `xorm:"varchar(100)" json:"email"`
+	Avatar      string `xorm:"varchar(200)" json:"avatar"`
+	CreatedTime string `xorm:"varchar(40)" json:"createdTime"`
+	Legacy      bool   `xorm:"bool" json:"-"`
+}
+
+// errLastLoginMethod rolls back the unlinking of the last linked identity.
+var errLastLoginMethod = errors.New("last login method")
+
+func GetLinkedAccount(provider, subject string) *LinkedAccount {
+	account := LinkedAccount{Provider: provider, Subject: subject}
+	existed, err := adapter.engine.Get(&account)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &account
+	}
+	return nil
+}
+
+// GetLinkedMember returns the id of the member the identity is linked with, empty if not linked.
+func GetLinkedMember(provider, subject string) string {
+	account := GetLinkedAccount(provider, subject)
+	if account == nil {
+		return ""
+	}
+	return account.MemberId
+}
+
+// GetIdentityMember returns the member the identity is linked with. A legacy identity matching legacySubject
+// is linked too, its subject is rewritten to the stable one, so that legacySubject is only matched once.
+func GetIdentityMember(provider, subject, legacySubject string) string {
+	if memberId := GetLinkedMember(provider, subject); memberId != "" || legacySubject == "" {
+		return memberId
+	}
+
+	affected, err := adapter.engine.Exec("UPDATE linked_account SET subject = ?, legacy = ? WHERE provider = ? AND subject = ? AND legacy = ?",
+		subject, false, provider, legacySubject, true)
+	if err != nil {
+		panic(err)
+	}
+	if num, _ := affected.RowsAffected(); num == 0 {
+		return ""
+	}
+
+	return GetLinkedMember(provider, subject)
+}
+
+func GetMemberLinkedAccounts(memberId string) []*LinkedAccount {
+	accounts := []*LinkedAccount{}
+	err := adapter.engine.Where("member_id = ?", memberId).Asc("created_time").Find(&accounts)
+	if err != nil {
+		panic(err)
+	}
+
+	return accounts
+}
+
+// AddLinkedAccount links the identity with the member, replacing the member's previous identity of the same provider.
+func AddLinkedAccount(account *LinkedAccount) bool {
+	_, err := adapter.engine.Where("member_id = ?", account.MemberId).And("provider = ?", account.Provider).Delete(&LinkedAccount{})
+	if err != nil {
+		panic(err)
+	}
+
+	affected, err := adapter.engine.Insert(account)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// UnlinkAccount unlinks the member's identity of the provider, unless it's the only way left to sign in:
+// the member has no password and no other linked identity.
+func UnlinkAccount(memberId, provider string) (bool, string) {
+	msg := ""
+	_, err := adapter.engine.Transaction(func(session *xorm.Session) (interface{}, error) {
+		// concurrent requests of the member are serialized by the row lock
+		member := Member{}
+		existed, err := session.Id(memberId).Cols("id, password").ForUpdate().Get(&member)
+		if err != nil {
+			return nil, err
+		}
+		if !existed {
+			msg = "Member doesn't exist."
+			return nil, nil
+		}
+
+		affected, err := session.Where("member_id = ?", memberId).And("provider = ?", provider).Delete(&LinkedAccount{})
+		if err != nil {
+			return nil, err
+		}
+		if affected == 0 {
+			msg = "The account isn't linked."
+			return nil, nil
+		}
+
+		if member.Password == "" {
+			remaining, err := session.Where("member_id = ?", memberId).Count(&LinkedAccount{})
+			if err != nil {
+				return nil, err
+			}
+			if remaining == 0 {
+				msg = "Please set a password or link another account first, this is the only way to sign in."
+				return nil, errLastLoginMethod
+			}
+		}
+		return nil, nil
+	})
+	if err != nil && err != errLastLoginMethod {
+		panic(err)
+	}
+
+	return msg == "", msg
+}
+
+func DeleteLinkedAccount(memberId, provider string) bool {
+	affected, err := adapter.engine.Where("member_id = ?", memberId).And("provider = ?", provider).Delete(&LinkedAccount{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// legacyAccountColumns are the provider columns the member table used to have,
+// mapped to the provider name and the columns of its subject, username and linked time.
+var legacyAccountColumns = map[string][]string{
+	"google": {"google_account", "google_account", ""},
+	"github": {"github_account", "github_account", ""},
+	"qq":     {"qq_open_id", "qq_account", "qq_verified_time"},
+	"wechat": {"wechat_open_id", "wechat_account", "wechat_verified_time"},
+}
+
+// migrateLinkedAccounts moves the legacy provider columns of the member table into linked_account, then drops them.
+func (a *Adapter) migrateLinkedAccounts() {
+	columns := map[string]bool{}
+	for _, cols := range legacyAccountColumns {
+		for _, col := range cols {
+			if col == "" || columns[col] {
+				continue
+			}
+			existed, err := a.engine.Dialect().IsColumnExist("member", col)
+			if err != nil {
+				panic(err)
+			}
+			if existed {
+				columns[col] = true
+			}
+		}
+	}
+	if len(columns) == 0 {
+		return
+	}
+
+	_, err := a.engine.Transaction(func(session *xorm.Session) (interface{}, error) {
+		for provider, cols := range legacyAccountColumns {
+			subjectCol, usernameCol, timeCol := cols[0], cols[1], cols[2]
+			if !columns[subjectCol] {
+				continue
+			}
+
+			rows, err := session.QueryString("select * from member where " + subjectCol + " <> ''")
+			if err != nil {
+				return nil, err
+			}
+			for _, row := range rows {
+				account := LinkedAccount{
+					Provider:    provider,
+					Subject:     row[subjectCol],
+					MemberId:    row["id"],
+					Username:    row[usernameCol],
+					CreatedTime: row["created_time"],
+				}
+				if provider == "google" {
+					account.Email = row[subjectCol]
+				}
+				// the google email and the github login could be changed or taken over, they are replaced by the stable ids
+				account.Legacy = provider == "google" || provider == "github"
+				if timeCol != "" && row[timeCol] != "" {
+					account.CreatedTime = row[timeCol]
+				}
+
+				existed, err := session.Exist(&LinkedAccount{Provider: account.Provider, Subject: account.Subject})
+				if err != nil {
+					return nil, err
+				}
+				if existed {
+					continue
+				}
+				_, err = session.Insert(&account)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	drops := []string{}
+	for col := range columns {
+		drops = append(drops, "drop column "+col)
+	}
+	_, err = a.engine.Exec("alter table member " + strings.Join(drops, ", "))
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"time"
+
+	"github.com/astaxie/beego/logs"
+	"github.com/casbin/casnode/service"
+	"github.com/casbin/casnode/util"
+)
+
+// QueuedMail is an outbound email. The pending mails are delivered by the mail worker when NextTime is reached,
+// a failed delivery is retried with backoff until MailMaxAttempts, then the mail is dead.
+type QueuedMail struct {
+	Id          int    `xorm:"int notnull pk autoincr" json:"id"`
+	Email       string `xorm:"varchar(100) index" json:"email"`
+	Subject     string `xorm:"varchar(500)" json:"subject"`
+	HtmlBody    string `xorm:"mediumtext" json:"htmlBody"`
+	TextBody    string `xorm:"mediumtext" json:"textBody"`
+	Secret      bool   `xorm:"bool" json:"secret"`
+	State       string `xorm:"varchar(20) index(state_next)" json:"state"`
+	Attempts    int    `xorm:"int" json:"attempts"`
+	LastError   string `xorm:"varchar(500)" json:"lastError"`
+	NextTime    string `xorm:"varchar(40) index(state_next)" json:"nextTime"`
+	CreatedTime string `xorm:"varchar(40)" json:"createdTime"`
+	SentTime    string `xorm:"varchar(40)" json:"sentTime"`
+}
+
+const (
+	MailStatePending = "pending"
+	MailStateSent    = "sent"
+	MailStateDead    = "dead"
+)
+
+// mailSignal wakes up the mail worker when a mail is enqueued.
+var mailSignal = make(chan bool, 1)
+
+// EnqueueMail saves the mail to be delivered by the mail worker, it never waits for SMTP.
+func EnqueueMail(mail *service.Mail) bool {
+	if mail.Email == "" {
+		return false
+	}
+
+	currentTime := util.GetCurrentTime()
+	queuedMail := QueuedMail{
+		Email:       mail.Email,
+		Subject:     mail.Subject,
+		HtmlBody:    mail.HtmlBody,
+		TextBody:    mail.TextBody,
+		Secret:      mail.Secret,
+		State:       MailStatePending,
+		NextTime:    currentTime,
+		CreatedTime: currentTime,
+	}
+	affected, err := adapter.engine.Insert(&queuedMail)
+	if err != nil {
+		panic(err)
+	}
+
+	select {
+	case mailSignal <- true:
+	default:
+	}
+	return affected != 0
+}
+
+// GetQueuedMails returns the mails of the state without the bodies, or of all the states if state is empty.
+func GetQueuedMails(state string, limit int, offset int) []*QueuedMail {
+	mails := []*QueuedMail{}
+	session := adapter.engine.Desc("id").Omit("html_body, text_body").Limit(limit, offset)
+	if state != "" {
+		session = session.Where("state = ?", state)
+	}
+	err := session.Find(&mails)
+	if err != nil {
+		panic(err)
+	}
+
+	return mails
+}
+
+func GetQueuedMailNum(state string) int {
+	session := adapter.engine.NewSession()
+	defer session.Close()
+	if state != "" {
+		session = session.Where("state = ?", state)
+	}
+	total, err := session.Count(&QueuedMail{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(total)
+}
+
+func GetQueuedMail(id int) *QueuedMail {
+	mail := QueuedMail{Id: id}
+	existed, err := adapter.engine.Get(&mail)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &mail
+	}
+	return nil
+}
+
+// RetryQueuedMail puts the dead mail back to the queue with its attempts reset.
+func RetryQueuedMail(id int) bool {
+	mail := QueuedMail{State: MailStatePending, NextTime: util.GetCurrentTime()}
+	affected, err := adapter.engine.Id(id).Where("state = ?", MailStateDead).Cols("state, attempts, next_time").Update(&mail)
+	if err != nil {
+		panic(err)
+	}
+
+	if affected != 0 {
+		select {
+		case mailSignal <- true:
+		default:
+		}
+	}
+	return affected != 0
+}
+
+func DeleteQueuedMail(id int) bool {
+	affected, err := adapter.engine.Id(id).Delete(&QueuedMail{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// DeleteExpiredMails deletes the mails sent before the date.
+func DeleteExpiredMails(date string) int {
+	affected, err := adapter.engine.Where("state = ?", MailStateSent).And("sent_time < ?", date).Delete(&QueuedMail{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(affected)
+}
+
+// getMailRetryTime returns when the mail is retried after the failed attempts, the interval is doubled after each of them.
+func getMailRetryTime(attempts int) string {
+	minutes := MailRetryInterval << uint(attempts-1)
+	return util.GetTimeMinute(minutes)
+}
+
+// claimQueuedMail takes the mail for delivery by counting the attempt and putting off NextTime, so that
+// the other workers skip it, and the mail is retried if this worker stops before finishing it.
+func claimQueuedMail(mail *QueuedMail) bool {
+	claimed := QueuedMail{Attempts: mail.Attempts + 1, NextTime: util.GetTimeMinute(MailSendingTimeout)}
+	affected, err := adapter.engine.Id(mail.Id).Where("state = ?", MailStatePending).And("next_time = ?", mail.NextTime).
+		Cols("attempts, next_time").Update(&claimed)
+	if err != nil {
+		panic(err)
+	}
+
+	mail.Attempts = claimed.Attempts
+	return affected != 0
+}
+
+func deliverQueuedMail(mail *QueuedMail) bool {
+	err := service.SendMail(&service.Mail{Email: mail.Email, Subject: mail.Subject, HtmlBody: mail.HtmlBody, TextBody: mail.TextBody})
+	if err == nil {
+		mail.State = MailStateSent
+		mail.SentTime = util.GetCurrentTime()
+		mail.LastError = ""
+		// the codes and links in secret mails are only needed until they are sent
+		if mail.Secret {
+			mail.HtmlBody = ""
+			mail.TextBody = ""
+		}
+	} else {
+		mail.LastError = err.Error()
+		if len(mail.LastError) > 500 {
+			mail.LastError = mail.LastError[:500]
+		}
+		if mail.Attempts >= MailMaxAttempts {
+			mail.State = MailStateDead
+		} else {
+			mail.NextTime = getMailRetryTime(mail.Attempts)
+		}
+	}
+
+	_, err = adapter.engine.Id(mail.Id).Cols("state, sent_time, last_error, next_time, html_body, text_body").Update(mail)
+	if err != nil {
+		panic(err)
+	}
+
+	return mail.State == MailStateSent
+}
+
+// DeliverMails delivers the pending mails which are due, returns the number of sent mails.
+func DeliverMails() int {
+	mails := []*QueuedMail{}
+	err := adapter.engine.Where("state = ?", MailStatePending).And("next_time <= ?", util.GetCurrentTime()).
+		Asc("next_time").Limit(MailBatchNum).Find(&mails)
+	if err != nil {
+		panic(err)
+	}
+
+	num := 0
+	for _, mail := range mails {
+		if !claimQueuedMail(mail) {
+			continue
+		}
+		if deliverQueuedMail(mail) {
+			num++
+		}
+	}
+
+	return num
+}
+
+func mailWorkerRoutine() {
+	ticker := time.NewTicker(time.Second * time.Duration(MailQueueInterval))
+	for {
+		func() {
+			defer func() {
+				if err := recover(); err != nil {
+					logs.Error("Deliver mails failed: %v", err)
+				}
+			}()
+
+			// a full batch means more mails may be due
+			for DeliverMails() == MailBatchNum {
+			}
+		}()
+
+		select {
+		case <-ticker.C:
+		case <-mailSignal:
+		}
+	}
+}
+
+// InitMailWorker starts delivering the queued mails in the background.
+func InitMailWorker() {
+	go mailWorkerRoutine()
+}
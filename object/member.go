@@ -17,14 +17,17 @@ package object
 import (
 	"bytes"
 	"strings"
+
+	"github.com/casbin/casnode/util"
 )
 
-// Member using figure 1-3 to show member's account status, 1 means normal, 2 means mute(couldn't reply or post new topic), 3 means forbidden(couldn't login).
+// Member using figure 1-4 to show member's account status, 1 means normal, 2 means mute(couldn't reply or post new topic), 3 means forbidden(couldn't login),
+// 4 means pending approval(signed up in the approval mode, couldn't reply or post new topic).
 type Member struct {
 	Id                 string `xorm:"varchar(100) notnull pk" json:"id"`
 	Password           string `xorm:"varchar(100) notnull" json:"-"`
 	No                 int    `json:"no"`
-	IsModerator        bool   `xorm:"bool" json:"isModerator"`
+	IsModerator        bool   `xorm:"-" json:"isModerator"` // whether the member has the admin role, see GetMemberRoles()
 	CreatedTime        string `xorm:"varchar(40)" json:"createdTime"`
 	Phone              string `xorm:"varchar(100)" json:"phone"`
 	AreaCode           string `xorm:"varchar(10)" json:"areaCode"` // phone area code
@@ -43,38 +46,108 @@ type Member struct {
 	Language           string `xorm:"varchar(10)"  json:"language"`
 	EditorType         string `xorm:"varchar(10)"  json:"editorType"`
 	FileQuota          int    `xorm:"int" json:"fileQuota"`
-	GoogleAccount      string `xorm:"varchar(100)" json:"googleAccount"`
-	GithubAccount      string `xorm:"varchar(100)" json:"githubAccount"`
-	WechatAccount      string `xorm:"varchar(100)" json:"weChatAccount"`
-	WechatOpenId       string `xorm:"varchar(100)" json:"-"`
-	WechatVerifiedTime string `xorm:"varchar(40)" json:"WechatVerifiedTime"`
-	QQAccount          string `xorm:"qq_account varchar(100)" json:"qqAccount"`
-	QQOpenId           string `xorm:"qq_open_id varchar(100)" json:"-"`
-	QQVerifiedTime     string `xorm:"qq_verified_time varchar(40)" json:"qqVerifiedTime"`
 	EmailReminder      bool   `xorm:"bool" json:"emailReminder"`
+	AutoWatchReply     bool   `xorm:"bool" json:"autoWatchReply"`
 	CheckinDate        string `xorm:"varchar(20)" json:"-"`
 	OnlineStatus       bool   `xorm:"bool" json:"onlineStatus"`
 	LastActionDate     string `xorm:"varchar(40)" json:"-"`
 	Status             int    `xorm:"int" json:"-"`
+	TotpSecret         string `xorm:"varchar(100)" json:"-"`
+	TotpEnabled        bool   `xorm:"bool" json:"totpEnabled"`
+	TotpLastStep       int64  `json:"-"`
+	SessionRevokedTime string `xorm:"varchar(40)" json:"-"`
+	TrustLevel         int    `xorm:"int" json:"trustLevel"`
+	TrustLevelPinned   bool   `xorm:"bool" json:"trustLevelPinned"` // pinned by admin, not recomputed
+	TrustLevelTime     string `xorm:"varchar(40)" json:"-"`         // when the trust level was computed
+	EmailPublic        bool   `xorm:"bool" json:"emailPublic"`      // the email is shown on the profile
+	PhonePublic        bool   `xorm:"bool" json:"phonePublic"`      // the phone is shown on the profile
+	AccountsPublic     bool   `xorm:"bool" json:"accountsPublic"`   // the linked accounts are shown on the profile
+	ThanksPublic       bool   `xorm:"bool" json:"thanksPublic"`     // the thanks given are shown in the timeline
+	FavoritesPublic    bool   `xorm:"bool" json:"favoritesPublic"`  // the favorite nodes and followed members are shown in the timeline
+	DigestFrequency    string `xorm:"varchar(10)" json:"digestFrequency"`
+	DigestSentTime     string `xorm:"varchar(40)" json:"-"`
+
+	LinkedAccounts []*LinkedAccount `xorm:"-" json:"linkedAccounts"`
+}
+
+// GetPublicMember returns the fields of the member which can be shown to others.
+func GetPublicMember(member *Member) *PublicMember {
+	res := PublicMember{
+		Id:           member.Id,
+		No:           member.No,
+		Avatar:       member.Avatar,
+		CreatedTime:  member.CreatedTime,
+		Tagline:      member.Tagline,
+		Company:      member.Company,
+		CompanyTitle: member.CompanyTitle,
+		Bio:          member.Bio,
+		Website:      member.Website,
+		Location:     member.Location,
+		ScoreCount:   member.ScoreCount,
+		OnlineStatus: member.OnlineStatus,
+		TrustLevel:   member.TrustLevel,
+	}
+
+	if member.EmailPublic && member.EmailVerifiedTime != "" {
+		res.Email = member.Email
+	}
+	if member.PhonePublic && member.PhoneVerifiedTime != "" {
+		res.Phone = member.Phone
+		res.AreaCode = member.AreaCode
+	}
+	if member.AccountsPublic {
+		res.LinkedAccounts = []*PublicLinkedAccount{}
+		for _, account := range GetMemberLinkedAccounts(member.Id) {
+			res.LinkedAccounts = append(res.LinkedAccounts, &PublicLinkedAccount{Provider: account.Provider, Username: account.Username})
+		}
+	}
+
+	return &res
 }
 
-func GetMembers() []*Member {
+// GetMemberDirectory returns the members whose names or taglines contain the keyword, sorted by "joined"
+// (newest first) or "active" (recently active first), and the total number of them.
+// Forbidden members and members pending approval are not listed.
+func GetMemberDirectory(keyword, sort string, limit int, offset int) ([]*PublicMember, int) {
+	session := adapter.engine.Where("status not in (?, ?)", 3, 4).And("id != ?", DeletedMemberId)
+	if keyword != "" {
+		like := "%" + keyword + "%"
+		session = session.And("(id like ? or tagline like ?)", like, like)
+	}
+
+	switch sort {
+	case "active":
+		session = session.Desc("last_action_date")
+	default:
+		session = session.Desc("created_time")
+	}
+
 	members := []*Member{}
-	err := adapter.engine.Asc("created_time").Find(&members)
+	num, err := session.Limit(limit, offset).FindAndCount(&members)
 	if err != nil {
 		panic(err)
 	}
 
-	return members
+	res := []*PublicMember{}
+	for _, member := range members {
+		res = append(res, GetPublicMember(member))
+	}
+
+	return res, int(num)
 }
 
-func GetRankingRich() []*Member {
+func GetRankingRich() []*PublicMember {
 	members := []*Member{}
 	err := adapter.engine.Desc("score_count").Limit(25, 0).Find(&members)
 	if err != nil {
 		panic(err)
 	}
-	return members
+
+	res := []*PublicMember{}
+	for _, member := range members {
+		res = append(res, GetPublicMember(member))
+	}
+	return res
 }
 
 // GetMembersAdmin cs, us: 1 means Asc, 2 means Desc, 0 means no effect.
@@ -135,6 +208,7 @@ func GetMemberAdmin(id string) *AdminMemberInfo {
 		panic(err)
 	}
 
+	member.LinkedAccounts = GetMemberLinkedAccounts(id)
 	res := AdminMemberInfo{
 		Member:        member,
 		FileQuota:     member.FileQuota,
@@ -209,7 +283,7 @@ func UpdateMemberInfo(id string, member *Member) bool {
 		return false
 	}
 
-	_, err := adapter.engine.Id(id).MustCols("company, bio, website, tagline, company_title, location").Update(member)
+	_, err := adapter.engine.Id(id).MustCols("company, bio, website, tagline, company_title, location, email_public, phone_public, accounts_public, thanks_public, favorites_public").Update(member)
 	if err != nil {
 		panic(err)
 	}
@@ -217,6 +291,28 @@ func UpdateMemberInfo(id string, member *Member) bool {
 	return true
 }
 
+// UpdateMemberEmail changes the member's email to the verified address.
+func UpdateMemberEmail(id, email string) bool {
+	member := Member{Email: email, EmailVerifiedTime: util.GetCurrentTime()}
+	affected, err := adapter.engine.Id(id).Cols("email, email_verified_time").Update(&member)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// UpdateMemberPhone changes the member's phone number to the verified number.
+func UpdateMemberPhone(id, phone, areaCode string) bool {
+	member := Member{Phone: phone, AreaCode: areaCode, PhoneVerifiedTime: util.GetCurrentTime()}
+	affected, err := adapter.engine.Id(id).Cols("phone, area_code, phone_verified_time").Update(&member)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
 // ChangeMemberEmailReminder change member's email reminder status
 func ChangeMemberEmailReminder(id, status string) bool {
 	if GetMember(id) == nil {
@@ -238,6 +334,38 @@ func ChangeMemberEmailReminder(id, status string) bool {
 	return true
 }
 
+// ChangeMemberAutoWatchReply changes whether member watches the topics he replies automatically.
+func ChangeMemberAutoWatchReply(id, status string) bool {
+	if GetMember(id) == nil {
+		return false
+	}
+
+	member := new(Member)
+	member.AutoWatchReply = status == "true"
+
+	_, err := adapter.engine.Id(id).MustCols("auto_watch_reply").Update(member)
+	if err != nil {
+		panic(err)
+	}
+
+	return true
+}
+
+// GetMemberAutoWatchReply returns whether member watches the topics he replies automatically.
+func GetMemberAutoWatchReply(id string) bool {
+	member := Member{}
+	existed, err := adapter.engine.Id(id).Cols("auto_watch_reply").Get(&member)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return member.AutoWatchReply
+	} else {
+		return false
+	}
+}
+
 func UpdateMemberAvatar(id string, avatar string) bool {
 	if GetMember(id) == nil {
 		return false
@@ -391,62 +519,6 @@ func GetPhoneNumber(phoneNumber string) *Member {
 	}
 }
 
-func GetGoogleAccount(googleAccount string) *Member {
-	member := Member{GoogleAccount: googleAccount}
-	existed, err := adapter.engine.Get(&member)
-	if err != nil {
-		panic(err)
-	}
-
-	if existed {
-		return &member
-	} else {
-		return nil
-	}
-}
-
-func GetQQAccount(qqOpenId string) *Member {
-	member := Member{QQOpenId: qqOpenId}
-	existed, err := adapter.engine.Get(&member)
-	if err != nil {
-		panic(err)
-	}
-
-	if existed {
-		return &member
-	} else {
-		return nil
-	}
-}
-
-func GetWechatAccount(wechatOpenId string) *Member {
-	member := Member{WechatOpenId: wechatOpenId}
-	existed, err := adapter.engine.Get(&member)
-	if err != nil {
-		panic(err)
-	}
-
-	if existed {
-		return &member
-	} else {
-		return nil
-	}
-}
-
-func GetGithubAccount(githubAccount string) *Member {
-	member := Member{GithubAccount: githubAccount}
-	existed, err := adapter.engine.Get(&member)
-	if err != nil {
-		panic(err)
-	}
-
-	if existed {
-		return &member
-	} else {
-		return nil
-	}
-}
-
 func LinkMemberAccount(memberId, field, value string) bool {
 	affected, err := adapter.engine.Table(new(Member)).ID(memberId).Update(map[string]interface{}{field: value})
 	if err != nil {
@@ -482,20 +554,6 @@ func UpdateMemberCheckinDate(id, date string) bool {
 	return affected != 0
 }
 
-func CheckModIdentity(memberId string) bool {
-	member := Member{}
-	existed, err := adapter.engine.Id(memberId).Cols("is_moderator").Get(&member)
-	if err != nil {
-		panic(err)
-	}
-
-	if existed {
-		return member.IsModerator
-	} else {
-		return false
-	}
-}
-
 func UpdateMemberPassword(id, password string) bool {
 	member := new(Member)
 	member.Password = password
@@ -505,6 +563,11 @@ func UpdateMemberPassword(id, password string) bool {
 		panic(err)
 	}
 
+	// whoever knew the old password is signed out
+	if affected != 0 {
+		RevokeMemberSessions(id, "")
+	}
+
 	return affected != 0
 }
 
@@ -526,12 +589,12 @@ func GetMemberFileQuota(memberId string) int {
 // Information could be phone member, email or username.
 // If success, return username.
 func MemberPasswordLogin(information, password string) string {
-	if len(password) == 0 || strings.Index(password, " ") >= 0{
+	if len(password) == 0 || strings.Index(password, " ") >= 0 {
 		return ""
 	}
 
 	member := Member{
-		Email: information,
+		Email:    information,
 		Password: password,
 	}
 	exist, err := adapter.engine.Get(&member)
@@ -543,7 +606,7 @@ func MemberPasswordLogin(information, password string) string {
 	}
 
 	member = Member{
-		Phone: information,
+		Phone:    information,
 		Password: password,
 	}
 	exist, err = adapter.engine.Get(&member)
@@ -555,7 +618,7 @@ func MemberPasswordLogin(information, password string) string {
 	}
 
 	member = Member{
-		Id: information,
+		Id:       information,
 		Password: password,
 	}
 	exist, err = adapter.engine.Get(&member)
@@ -0,0 +1,117 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"xorm.io/xorm"
+)
+
+// MemberBlock means MemberId has blocked BlockedId: the topics of BlockedId are hidden from MemberId,
+// the replies are collapsed, and MemberId gets no notifications or thanks from BlockedId.
+type MemberBlock struct {
+	Id          int    `xorm:"int notnull pk autoincr" json:"id"`
+	MemberId    string `xorm:"varchar(100) unique(member_blocked)" json:"memberId"`
+	BlockedId   string `xorm:"varchar(100) unique(member_blocked) index" json:"blockedId"`
+	CreatedTime string `xorm:"varchar(40)" json:"createdTime"`
+}
+
+func AddMemberBlock(block *MemberBlock) bool {
+	if IsMemberBlocked(block.MemberId, block.BlockedId) {
+		return true
+	}
+
+	affected, err := adapter.engine.Insert(block)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func DeleteMemberBlock(memberId, blockedId string) bool {
+	affected, err := adapter.engine.Where("member_id = ?", memberId).And("blocked_id = ?", blockedId).Delete(&MemberBlock{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func GetMemberBlocks(memberId string) []*MemberBlock {
+	blocks := []*MemberBlock{}
+	err := adapter.engine.Where("member_id = ?", memberId).Desc("id").Find(&blocks)
+	if err != nil {
+		panic(err)
+	}
+
+	return blocks
+}
+
+// GetBlockedMemberIds returns the members blocked by the member.
+func GetBlockedMemberIds(memberId string) []string {
+	ids := []string{}
+	if memberId == "" {
+		return ids
+	}
+
+	err := adapter.engine.Table("member_block").Where("member_id = ?", memberId).Cols("blocked_id").Find(&ids)
+	if err != nil {
+		panic(err)
+	}
+
+	return ids
+}
+
+// GetBlockerIds returns the members who have blocked the member.
+func GetBlockerIds(blockedId string) []string {
+	ids := []string{}
+	err := adapter.engine.Table("member_block").Where("blocked_id = ?", blockedId).Cols("member_id").Find(&ids)
+	if err != nil {
+		panic(err)
+	}
+
+	return ids
+}
+
+// IsMemberBlocked returns whether the member has blocked blockedId.
+func IsMemberBlocked(memberId, blockedId string) bool {
+	if memberId == "" || blockedId == "" {
+		return false
+	}
+
+	existed, err := adapter.engine.Where("member_id = ?", memberId).And("blocked_id = ?", blockedId).Exist(&MemberBlock{})
+	if err != nil {
+		panic(err)
+	}
+
+	return existed
+}
+
+// deleteBlockers removes the members who have blocked the sender from the receivers.
+func deleteBlockers(receivers map[string]bool, senderId string) {
+	for _, id := range GetBlockerIds(senderId) {
+		delete(receivers, id)
+	}
+}
+
+// excludeBlockedAuthors filters out the topics of the members blocked by memberId.
+func excludeBlockedAuthors(session *xorm.Session, memberId string) *xorm.Session {
+	blockedIds := GetBlockedMemberIds(memberId)
+	if len(blockedIds) == 0 {
+		return session
+	}
+
+	return session.NotIn("topic.author", blockedIds)
+}
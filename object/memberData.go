@@ -0,0 +1,219 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/hex"
+
+	"xorm.io/xorm"
+
+	"github.com/casbin/casnode/util"
+)
+
+// DeletedMemberId is the placeholder author of the topics and replies of deleted members.
+var DeletedMemberId = "deleted_member"
+
+// MemberExport is all the data of a member, for the member to download.
+type MemberExport struct {
+	ExportedTime       string               `json:"exportedTime"`
+	Profile            *Member              `json:"profile"`
+	Topics             []*Topic             `json:"topics"`
+	Replies            []*Reply             `json:"replies"`
+	Favorites          []*Favorites         `json:"favorites"`
+	ConsumptionRecords []*ConsumptionRecord `json:"consumptionRecords"`
+	Notifications      []*Notification      `json:"notifications"`
+	Files              []*UploadFileRecord  `json:"files"`
+	TopicWatches       []*TopicWatch        `json:"topicWatches"`
+	AccessTokens       []*AccessToken       `json:"accessTokens"`
+	Sessions           []*SessionRecord     `json:"sessions"`
+}
+
+func GetMemberExport(memberId string) *MemberExport {
+	member := GetMember(memberId)
+	if member == nil {
+		return nil
+	}
+	member.LinkedAccounts = GetMemberLinkedAccounts(memberId)
+
+	export := MemberExport{
+		ExportedTime:       util.GetCurrentTime(),
+		Profile:            member,
+		Topics:             []*Topic{},
+		Replies:            []*Reply{},
+		Favorites:          []*Favorites{},
+		ConsumptionRecords: []*ConsumptionRecord{},
+		Notifications:      []*Notification{},
+		Files:              []*UploadFileRecord{},
+		TopicWatches:       []*TopicWatch{},
+		AccessTokens:       GetMemberAccessTokens(memberId),
+		Sessions:           GetMemberSessionRecords(memberId),
+	}
+
+	err := adapter.engine.Where("author = ?", memberId).And("deleted = ?", false).Asc("id").Find(&export.Topics)
+	if err != nil {
+		panic(err)
+	}
+	for _, topic := range export.Topics {
+		topic.Fields = GetTopicFields(topic.Id)
+	}
+
+	err = adapter.engine.Where("author = ?", memberId).And("deleted = ?", false).Asc("id").Find(&export.Replies)
+	if err != nil {
+		panic(err)
+	}
+
+	err = adapter.engine.Where("member_id = ?", memberId).Asc("id").Find(&export.Favorites)
+	if err != nil {
+		panic(err)
+	}
+
+	err = adapter.engine.Where("consumer_id = ?", memberId).Asc("id").Find(&export.ConsumptionRecords)
+	if err != nil {
+		panic(err)
+	}
+
+	err = adapter.engine.Where("receiver_id = ?", memberId).Asc("id").Find(&export.Notifications)
+	if err != nil {
+		panic(err)
+	}
+
+	err = adapter.engine.Where("member_id = ?", memberId).And("deleted = ?", false).Asc("id").Find(&export.Files)
+	if err != nil {
+		panic(err)
+	}
+
+	err = adapter.engine.Where("member_id = ?", memberId).Asc("id").Find(&export.TopicWatches)
+	if err != nil {
+		panic(err)
+	}
+
+	return &export
+}
+
+// addDeletedMember adds the placeholder member, so that the profile of the placeholder author could be shown.
+func addDeletedMember(session *xorm.Session) error {
+	existed, err := session.Id(DeletedMemberId).Exist(&Member{})
+	if err != nil || existed {
+		return err
+	}
+
+	member := Member{
+		Id:          DeletedMemberId,
+		Password:    hex.EncodeToString(util.GetRandomBytes(20)),
+		CreatedTime: util.GetCurrentTime(),
+		Status:      3,
+	}
+	_, err = session.Insert(&member)
+	return err
+}
+
+// DeleteMemberAccount deletes the member, the topics and replies are kept under DeletedMemberId,
+// everything else of the member is removed. Returns the paths of the uploaded files to be removed from the storage.
+func DeleteMemberAccount(memberId string) (bool, []string) {
+	member := GetMember(memberId)
+	if member == nil || memberId == DeletedMemberId {
+		return false, nil
+	}
+
+	files := []*UploadFileRecord{}
+	err := adapter.engine.Where("member_id = ?", memberId).Find(&files)
+	if err != nil {
+		panic(err)
+	}
+	paths := []string{}
+	for _, file := range files {
+		if !file.Deleted {
+			paths = append(paths, file.FilePath)
+		}
+	}
+
+	sessionRecords := GetMemberSessionRecords(memberId)
+
+	_, err = adapter.engine.Transaction(func(session *xorm.Session) (interface{}, error) {
+		err := addDeletedMember(session)
+		if err != nil {
+			return nil, err
+		}
+
+		// the content is kept under the placeholder author
+		updates := []struct {
+			table, column string
+		}{
+			{"topic", "author"},
+			{"topic", "last_reply_user"},
+			{"reply", "author"},
+			{"invite_code", "used_by"},
+			{"notification", "sender_id"},
+			{"consumption_record", "receiver_id"},
+		}
+		for _, update := range updates {
+			_, err = session.Exec("UPDATE "+update.table+" SET "+update.column+" = ? WHERE "+update.column+" = ?", DeletedMemberId, memberId)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// the rest is removed
+		deletes := []struct {
+			bean  interface{}
+			query string
+			args  []interface{}
+		}{
+			{&Favorites{}, "member_id = ? or (favorites_type = 2 and object_id = ?)", []interface{}{memberId, memberId}},
+			{&ConsumptionRecord{}, "consumer_id = ?", []interface{}{memberId}},
+			{&Notification{}, "receiver_id = ?", []interface{}{memberId}},
+			{&BrowseRecord{}, "member_id = ?", []interface{}{memberId}},
+			{&ResetRecord{}, "member_id = ?", []interface{}{memberId}},
+			{&UploadFileRecord{}, "member_id = ?", []interface{}{memberId}},
+			{&TopicWatch{}, "member_id = ?", []interface{}{memberId}},
+			{&UsernameChange{}, "member_id = ?", []interface{}{memberId}},
+			{&MemberBlock{}, "member_id = ? or blocked_id = ?", []interface{}{memberId, memberId}},
+			{&InviteCode{}, "creator_id = ?", []interface{}{memberId}},
+			{&LinkedAccount{}, "member_id = ?", []interface{}{memberId}},
+			{&RecoveryCode{}, "member_id = ?", []interface{}{memberId}},
+			{&AccessToken{}, "member_id = ?", []interface{}{memberId}},
+			{&SessionRecord{}, "member_id = ?", []interface{}{memberId}},
+			{&MemberBadge{}, "member_id = ?", []interface{}{memberId}},
+			{&Suspension{}, "member_id = ?", []interface{}{memberId}},
+			{&NotificationPreference{}, "member_id = ?", []interface{}{memberId}},
+			{&AuthAttempt{}, "action = ? and target in (?, ?, ?)", []interface{}{AuthActionSignin, GetSigninTarget(memberId), GetSigninTarget(member.Email), GetSigninTarget(member.Phone)}},
+			{&ValidateCode{}, "information in (?, ?)", []interface{}{member.Email, member.Phone}},
+			{&QueuedMail{}, "email = ?", []interface{}{member.Email}},
+		}
+		for _, v := range deletes {
+			_, err = session.Where(v.query, v.args...).Delete(v.bean)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, record := range sessionRecords {
+			_, err = session.Where("session_key = ?", record.SessionKey).Delete(&Session{})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		_, err = session.Id(memberId).Delete(&Member{})
+		return nil, err
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	DeleteMemberRoles(memberId)
+
+	return true, paths
+}
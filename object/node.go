@@ -30,7 +30,10 @@ type Node struct {
 	PlaneId          string   `xorm:"varchar(50)" json:"planeId"`
 	Sorter           int      `xorm:"int" json:"sorter"`
 	Hot              int      `xorm:"int" json:"hot"`
-	Moderators       []string `xorm:"varchar(200)" json:"moderators"`
+	Moderators       []string `xorm:"-" json:"moderators"` // filled in with the moderator role of the node
+
+	Policy   *NodePolicy   `xorm:"text json" json:"policy"`
+	Template *NodeTemplate `xorm:"mediumtext json" json:"template"`
 }
 
 func GetNodes() []*Node {
@@ -40,6 +43,9 @@ func GetNodes() []*Node {
 		panic(err)
 	}
 
+	for _, node := range nodes {
+		node.Moderators = GetNodeModerators(node.Id)
+	}
 	return nodes
 }
 
@@ -51,6 +57,7 @@ func GetNode(id string) *Node {
 	}
 
 	if existed {
+		node.Moderators = GetNodeModerators(id)
 		return &node
 	} else {
 		return nil
@@ -86,6 +93,9 @@ func DeleteNode(id string) bool {
 		panic(err)
 	}
 
+	if affected != 0 {
+		deleteDomainRoles(GetNodeDomain(id))
+	}
 	return affected != 0
 }
 
@@ -221,71 +231,20 @@ func UpdateNodeHotInfo(nodeId string, hot int) bool {
 	return affected != 0
 }
 
+// GetNodeModerators returns the members with the moderator role of the node.
 func GetNodeModerators(id string) []string {
-	node := Node{Id: id}
-	existed, err := adapter.engine.Cols("moderators").Get(&node)
-	if err != nil {
-		panic(err)
+	moderators := []string{}
+	for _, role := range GetRoleMembers(RoleModerator, GetNodeDomain(id)) {
+		moderators = append(moderators, role.MemberId)
 	}
 
-	if existed {
-		return node.Moderators
-	} else {
-		return nil
-	}
-}
-
-func CheckNodeModerator(memberId, nodeId string) bool {
-	node := Node{Id: nodeId}
-	existed, err := adapter.engine.Cols("moderators").Get(&node)
-	if err != nil {
-		panic(err)
-	}
-
-	if !existed || len(node.Moderators) == 0 {
-		return false
-	}
-	for _, v := range node.Moderators {
-		if v == memberId {
-			return true
-		}
-	}
-	return false
+	return moderators
 }
 
 func AddNodeModerators(memberId, nodeId string) bool {
-	node := new(Node)
-
-	moderators := GetNodeModerators(nodeId)
-	for _, v := range moderators {
-		if v == memberId {
-			return false
-		}
-	}
-	node.Moderators = append(moderators, memberId)
-	affected, err := adapter.engine.Id(nodeId).Cols("moderators").Update(node)
-	if err != nil {
-		panic(err)
-	}
-
-	return affected != 0
+	return AddMemberRole(memberId, RoleModerator, GetNodeDomain(nodeId))
 }
 
 func DeleteNodeModerators(memberId, nodeId string) bool {
-	node := new(Node)
-
-	moderators := GetNodeModerators(nodeId)
-	for i, v := range moderators {
-		if v == memberId {
-			moderators = append(moderators[:i], moderators[i+1:]...)
-			break
-		}
-	}
-	node.Moderators = moderators
-	affected, err := adapter.engine.Id(nodeId).Cols("moderators").Update(node)
-	if err != nil {
-		panic(err)
-	}
-
-	return affected != 0
+	return DeleteMemberRole(memberId, RoleModerator, GetNodeDomain(nodeId))
 }
@@ -0,0 +1,127 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "github.com/casbin/casnode/util"
+
+// NodePolicy overrides the global posting settings for a single node.
+// A nil field (or an empty EditorTypes) means the global value in conf.go is used.
+type NodePolicy struct {
+	TopicEditableTime  *float64 `json:"topicEditableTime"`  // minutes
+	ReplyEditableTime  *float64 `json:"replyEditableTime"`  // minutes
+	ReplyDeletableTime *float64 `json:"replyDeletableTime"` // minutes
+	CreateTopicCost    *int     `json:"createTopicCost"`
+	CreateReplyCost    *int     `json:"createReplyCost"`
+	EditorTypes        []string `json:"editorTypes"`   // allowed editor types, e.g. markdown, richtext
+	MinAccountAge      int      `json:"minAccountAge"` // days
+	MinTrustLevel      int      `json:"minTrustLevel"` // for creating topics
+}
+
+// EffectivePolicy is the node policy with all the global defaults filled in.
+type EffectivePolicy struct {
+	TopicEditableTime  float64  `json:"topicEditableTime"`
+	ReplyEditableTime  float64  `json:"replyEditableTime"`
+	ReplyDeletableTime float64  `json:"replyDeletableTime"`
+	CreateTopicCost    int      `json:"createTopicCost"`
+	CreateReplyCost    int      `json:"createReplyCost"`
+	EditorTypes        []string `json:"editorTypes"`
+	MinAccountAge      int      `json:"minAccountAge"`
+	MinTrustLevel      int      `json:"minTrustLevel"`
+}
+
+// GetEffectiveNodePolicy returns the policy of the node, falling back to the global settings.
+// The global settings are returned for the empty node id, e.g. the node of a missing topic.
+func GetEffectiveNodePolicy(nodeId string) *EffectivePolicy {
+	policy := EffectivePolicy{
+		TopicEditableTime:  TopicEditableTime,
+		ReplyEditableTime:  ReplyEditableTime,
+		ReplyDeletableTime: ReplyDeletableTime,
+		CreateTopicCost:    CreateTopicCost,
+		CreateReplyCost:    CreateReplyCost,
+	}
+	if nodeId == "" {
+		return &policy
+	}
+
+	node := Node{Id: nodeId}
+	existed, err := adapter.engine.Cols("policy").Get(&node)
+	if err != nil {
+		panic(err)
+	}
+	if !existed || node.Policy == nil {
+		return &policy
+	}
+
+	p := node.Policy
+	if p.TopicEditableTime != nil {
+		policy.TopicEditableTime = *p.TopicEditableTime
+	}
+	if p.ReplyEditableTime != nil {
+		policy.ReplyEditableTime = *p.ReplyEditableTime
+	}
+	if p.ReplyDeletableTime != nil {
+		policy.ReplyDeletableTime = *p.ReplyDeletableTime
+	}
+	if p.CreateTopicCost != nil {
+		policy.CreateTopicCost = *p.CreateTopicCost
+	}
+	if p.CreateReplyCost != nil {
+		policy.CreateReplyCost = *p.CreateReplyCost
+	}
+	policy.EditorTypes = p.EditorTypes
+	policy.MinAccountAge = p.MinAccountAge
+	policy.MinTrustLevel = p.MinTrustLevel
+
+	return &policy
+}
+
+// EditorTypeAllowed checks whether the editor type can be used in the node.
+func (p *EffectivePolicy) EditorTypeAllowed(editorType string) bool {
+	if len(p.EditorTypes) == 0 {
+		return true
+	}
+	if editorType == "" {
+		editorType = "markdown"
+	}
+
+	for _, v := range p.EditorTypes {
+		if v == editorType {
+			return true
+		}
+	}
+	return false
+}
+
+// AccountOldEnough checks whether the member has been registered for at least MinAccountAge days.
+func (p *EffectivePolicy) AccountOldEnough(memberId string) bool {
+	if p.MinAccountAge <= 0 {
+		return true
+	}
+
+	member := GetMember(memberId)
+	if member == nil {
+		return false
+	}
+	return member.CreatedTime <= util.GetTimeDay(-p.MinAccountAge)
+}
+
+// TrustLevelEnough checks whether the member's trust level allows creating topics in the node.
+func (p *EffectivePolicy) TrustLevelEnough(memberId string) bool {
+	if p.MinTrustLevel <= 0 {
+		return true
+	}
+
+	return GetMemberTrustLevel(memberId) >= p.MinTrustLevel
+}
@@ -0,0 +1,176 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"xorm.io/xorm"
+)
+
+// NotificationPreference is how the member receives the notifications of the category: in the notification
+// list, by email right away, or in the digest email. Only the categories changed by the member are stored,
+// see defaultNotificationPreference().
+type NotificationPreference struct {
+	MemberId string `xorm:"varchar(100) notnull pk" json:"memberId"`
+	Category string `xorm:"varchar(40) notnull pk" json:"category"`
+	InApp    bool   `xorm:"bool" json:"inApp"`
+	Email    bool   `xorm:"bool" json:"email"`
+	Digest   bool   `xorm:"bool" json:"digest"`
+}
+
+const (
+	NotificationCategoryReply      = "reply"      // replies to my topics
+	NotificationCategoryMention    = "mention"    // mentioned in topics or replies
+	NotificationCategoryFavorite   = "favorite"   // my topics added to favorites
+	NotificationCategoryFollow     = "follow"     // new followers
+	NotificationCategoryThanks     = "thanks"     // thanks of my topics or replies
+	NotificationCategoryModeration = "moderation" // suspensions and badges
+
+	NotificationChannelInApp  = "inApp"
+	NotificationChannelEmail  = "email"
+	NotificationChannelDigest = "digest"
+)
+
+var NotificationCategories = []string{
+	NotificationCategoryReply,
+	NotificationCategoryMention,
+	NotificationCategoryFavorite,
+	NotificationCategoryFollow,
+	NotificationCategoryThanks,
+	NotificationCategoryModeration,
+}
+
+// GetNotificationCategory returns the preference category of the notification type.
+func GetNotificationCategory(notificationType int) string {
+	switch notificationType {
+	case 1:
+		return NotificationCategoryReply
+	case 2, 3:
+		return NotificationCategoryMention
+	case 4:
+		return NotificationCategoryFavorite
+	case 5, 6:
+		return NotificationCategoryThanks
+	case 7, 8, 9:
+		return NotificationCategoryModeration
+	case 10:
+		return NotificationCategoryFollow
+	}
+	return ""
+}
+
+func IsValidNotificationCategory(category string) bool {
+	for _, v := range NotificationCategories {
+		if v == category {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultNotificationPreference returns the preference of the category the member hasn't changed.
+// Replies and mentions are emailed if the member has turned on the email reminder.
+func defaultNotificationPreference(memberId, category string, emailReminder bool) *NotificationPreference {
+	preference := NotificationPreference{
+		MemberId: memberId,
+		Category: category,
+		InApp:    true,
+	}
+	if category == NotificationCategoryReply || category == NotificationCategoryMention {
+		preference.Email = emailReminder
+	}
+	return &preference
+}
+
+// GetNotificationPreferences returns the preferences of all the categories.
+func GetNotificationPreferences(memberId string) []*NotificationPreference {
+	preferences := []*NotificationPreference{}
+	err := adapter.engine.Where("member_id = ?", memberId).Find(&preferences)
+	if err != nil {
+		panic(err)
+	}
+
+	stored := map[string]*NotificationPreference{}
+	for _, preference := range preferences {
+		stored[preference.Category] = preference
+	}
+
+	emailReminder, _ := GetMemberEmailReminder(memberId)
+	res := []*NotificationPreference{}
+	for _, category := range NotificationCategories {
+		if preference, ok := stored[category]; ok {
+			res = append(res, preference)
+		} else {
+			res = append(res, defaultNotificationPreference(memberId, category, emailReminder))
+		}
+	}
+	return res
+}
+
+func GetNotificationPreference(memberId, category string) *NotificationPreference {
+	preference := NotificationPreference{MemberId: memberId, Category: category}
+	existed, err := adapter.engine.Get(&preference)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &preference
+	}
+	emailReminder, _ := GetMemberEmailReminder(memberId)
+	return defaultNotificationPreference(memberId, category, emailReminder)
+}
+
+// UpdateNotificationPreferences saves the preferences of the member, the categories not given are kept.
+func UpdateNotificationPreferences(memberId string, preferences []*NotificationPreference) bool {
+	_, err := adapter.engine.Transaction(func(session *xorm.Session) (interface{}, error) {
+		for _, preference := range preferences {
+			preference.MemberId = memberId
+			_, err := session.Where("member_id = ?", memberId).And("category = ?", preference.Category).Delete(&NotificationPreference{})
+			if err != nil {
+				return nil, err
+			}
+
+			_, err = session.Insert(preference)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return true
+}
+
+// IsNotificationEnabled returns whether the member receives the notifications of the type by the channel.
+func IsNotificationEnabled(memberId string, notificationType int, channel string) bool {
+	category := GetNotificationCategory(notificationType)
+	if category == "" {
+		return channel == NotificationChannelInApp
+	}
+
+	preference := GetNotificationPreference(memberId, category)
+	switch channel {
+	case NotificationChannelInApp:
+		return preference.InApp
+	case NotificationChannelEmail:
+		return preference.Email
+	case NotificationChannelDigest:
+		return preference.Digest
+	}
+	return false
+}
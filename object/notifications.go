@@ -22,8 +22,9 @@ import (
 	"github.com/casbin/casnode/util"
 )
 
-// NotificationType 1-6 means: reply(topic), mentioned(reply), mentioned(topic), favorite(topic), thanks(topic), thanks(reply)
-// Status 1-3 means: unread, have read, deleted
+// NotificationType 1-10 means: reply(topic), mentioned(reply), mentioned(topic), favorite(topic), thanks(topic), thanks(reply), badge(member badge),
+// suspended(suspension), suspension lifted(suspension), followed(none)
+// Status 1-4 means: unread, have read, deleted, digest only (the receiver turned off the in-app notifications of the type)
 type Notification struct {
 	Id               int    `xorm:"int notnull pk autoincr" json:"id"`
 	NotificationType int    `xorm:"int index" json:"notificationType"`
@@ -35,19 +36,35 @@ type Notification struct {
 	//Deleted        bool   `xorm:"bool" json:"-"`
 }
 
+// AddNotification adds the notification unless the receiver has blocked the sender,
+// or has turned off both the in-app and the digest notifications of the type.
+// It's kept for the digest only if the in-app notifications of the type are turned off.
 func AddNotification(notification *Notification) bool {
+	if IsMemberBlocked(notification.ReceiverId, notification.SenderId) {
+		return false
+	}
+	if !IsNotificationEnabled(notification.ReceiverId, notification.NotificationType, NotificationChannelInApp) {
+		if !IsNotificationEnabled(notification.ReceiverId, notification.NotificationType, NotificationChannelDigest) {
+			return false
+		}
+		notification.Status = 4
+	}
+
 	affected, err := adapter.engine.Insert(notification)
 	if err != nil {
 		panic(err)
 	}
 
+	if affected != 0 && notification.Status != 4 {
+		publishNotification(notification)
+	}
 	return affected != 0
 }
 
-func DeleteNotification(id string) bool {
+func DeleteNotification(memberId, id string) bool {
 	notification := new(Notification)
 	notification.Status = 3
-	affected, err := adapter.engine.Id(id).Update(notification)
+	affected, err := adapter.engine.Id(id).Where("receiver_id = ?", memberId).Update(notification)
 	if err != nil {
 		panic(err)
 	}
@@ -67,7 +84,7 @@ func GetNotificationCount() int {
 func GetNotifications(memberId string, limit int, offset int) []*NotificationResponse {
 	notifications := []*NotificationResponse{}
 	err := adapter.engine.Table("notification").Join("LEFT OUTER", "member", "notification.sender_id = member.id").
-		Where("notification.receiver_id = ?", memberId).And("notification.status != ?", 3).
+		Where("notification.receiver_id = ?", memberId).In("notification.status", 1, 2).
 		Desc("notification.created_time").
 		Cols("notification.*, member.avatar").
 		Limit(limit, offset).Find(&notifications)
@@ -84,29 +101,7 @@ func GetNotifications(memberId string, limit int, offset int) []*NotificationRes
 		k := k
 		go func() {
 			defer wg.Done()
-			switch v.NotificationType {
-			case 1:
-
-				replyInfo := GetReply(v.ObjectId)
-				v.Title = GetReplyTopicTitle(replyInfo.TopicId)
-				v.Content = replyInfo.Content
-				v.ObjectId = replyInfo.TopicId
-			case 2:
-				replyInfo := GetReply(v.ObjectId)
-				v.Title = GetReplyTopicTitle(replyInfo.TopicId)
-				v.Content = replyInfo.Content
-				v.ObjectId = replyInfo.TopicId
-			case 3:
-				v.Title = GetTopicTitle(v.ObjectId)
-			case 4:
-				v.Title = GetTopicTitle(v.ObjectId)
-			case 5:
-				v.Title = GetTopicTitle(v.ObjectId)
-			case 6:
-				replyInfo := GetReply(v.ObjectId)
-				v.Title = GetReplyTopicTitle(replyInfo.TopicId)
-				v.Content = replyInfo.Content
-			}
+			fillNotification(v)
 			res[k] = v
 		}()
 	}
@@ -121,12 +116,66 @@ func GetNotifications(memberId string, limit int, offset int) []*NotificationRes
 	return res
 }
 
+// fillNotification fills the title and content of the notification's object, the ObjectId of replies
+// and mentions in replies becomes the topic.
+func fillNotification(v *NotificationResponse) {
+	switch v.NotificationType {
+	case 1:
+		replyInfo := GetReply(v.ObjectId)
+		v.Title = GetReplyTopicTitle(replyInfo.TopicId)
+		v.Content = replyInfo.Content
+		v.ObjectId = replyInfo.TopicId
+	case 2:
+		replyInfo := GetReply(v.ObjectId)
+		v.Title = GetReplyTopicTitle(replyInfo.TopicId)
+		v.Content = replyInfo.Content
+		v.ObjectId = replyInfo.TopicId
+	case 3:
+		v.Title = GetTopicTitle(v.ObjectId)
+	case 4:
+		v.Title = GetTopicTitle(v.ObjectId)
+	case 5:
+		v.Title = GetTopicTitle(v.ObjectId)
+	case 6:
+		replyInfo := GetReply(v.ObjectId)
+		v.Title = GetReplyTopicTitle(replyInfo.TopicId)
+		v.Content = replyInfo.Content
+	case 7:
+		v.Title = GetMemberBadgeName(v.ObjectId)
+	case 8, 9:
+		if suspension := GetSuspension(v.ObjectId); suspension != nil {
+			v.Title = suspension.Type
+			v.Content = suspension.Reason
+		}
+	case 10:
+		v.Title = v.SenderId
+	}
+}
+
+// GetUnreadNotificationsAfter returns the unread notifications after the id, for the clients catching up.
+func GetUnreadNotificationsAfter(memberId string, id int) []*NotificationResponse {
+	notifications := []*NotificationResponse{}
+	err := adapter.engine.Table("notification").Join("LEFT OUTER", "member", "notification.sender_id = member.id").
+		Where("notification.receiver_id = ?", memberId).And("notification.status = ?", 1).And("notification.id > ?", id).
+		Asc("notification.id").
+		Cols("notification.*, member.avatar").
+		Limit(DefaultPageNum).Find(&notifications)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, notification := range notifications {
+		fillNotification(notification)
+	}
+	return notifications
+}
+
 func GetNotificationNum(memberId string) int {
 	var total int64
 	var err error
 
 	notification := new(Notification)
-	total, err = adapter.engine.Where("receiver_id = ?", memberId).And("status != ?", 3).Count(notification)
+	total, err = adapter.engine.Where("receiver_id = ?", memberId).In("status", 1, 2).Count(notification)
 	if err != nil {
 		panic(err)
 	}
@@ -160,65 +209,103 @@ func GetNotificationId() int {
 func UpdateReadStatus(id string) bool {
 	notification := new(Notification)
 	notification.Status = 2
-	affected, err := adapter.engine.Where("receiver_id = ?", id).Cols("status").Update(notification)
+	affected, err := adapter.engine.Where("receiver_id = ?", id).And("status = ?", 1).Cols("status").Update(notification)
 	if err != nil {
 		panic(err)
 	}
 
+	publishUnreadNum(id)
 	return affected != 0
 }
 
-func AddReplyNotification(senderId, content string, objectId, topicId int) {
+// getMentionedMembers returns members mentioned in the content, except the sender.
+func getMentionedMembers(senderId, content string) map[string]bool {
 	memberMap := make(map[string]bool)
 
-	topicInfo := GetTopicBasicInfo(topicId)
-	receiverId := topicInfo.Author
-	memberMap[receiverId] = true
-
 	reg := regexp.MustCompile("@(.*?)[ \n\t]")
 	reg2 := regexp.MustCompile("@([^ \n\t]*?)[^ \n\t]$")
 	regResult := reg.FindAllStringSubmatch(content, -1)
 	regResult2 := reg2.FindAllStringSubmatch(content, -1)
 
+	// members could be mentioned by their old names
 	for _, v := range regResult {
-		if senderId != v[1] && !memberMap[v[1]] {
-			memberMap[v[1]] = true
+		id := ResolveMemberId(v[1])
+		if senderId != id && !memberMap[id] {
+			memberMap[id] = true
 		}
 	}
 
 	for _, v := range regResult2 {
-		v[1] += content[len(content)-1:]
-		if senderId != v[1] && !memberMap[v[1]] {
-			memberMap[v[1]] = true
+		id := ResolveMemberId(v[1] + content[len(content)-1:])
+		if senderId != id && !memberMap[id] {
+			memberMap[id] = true
 		}
 	}
 
-	var wg sync.WaitGroup
+	return memberMap
+}
+
+// sendNotificationMail emails the notification to the member if the member receives the notifications
+// of the type by email. topicId is 0 if the notification isn't about a topic.
+func sendNotificationMail(memberId string, notificationType int, title, content string, topicId int) {
+	_, email := GetMemberEmailReminder(memberId)
+	if email == "" || !IsNotificationEnabled(memberId, notificationType, NotificationChannelEmail) {
+		return
+	}
+
+	if topicId != 0 {
+		EnqueueMail(service.NewRemindMail(title, content, util.IntToString(topicId), email, Domain))
+	} else {
+		EnqueueMail(service.NewNotificationMail(title, content, email, Domain))
+	}
+}
+
+// AddReplyNotification notifies members watching the topic of the new reply,
+// and notifies mentioned members unless they have muted the topic.
+func AddReplyNotification(senderId, content string, objectId, topicId int) {
+	topicInfo := GetTopicBasicInfo(topicId)
+	author := topicInfo.Author
 
-	if senderId != receiverId {
-		notification := Notification{
-			//Id:               memberMap[receiverId],
-			NotificationType: 1,
-			ObjectId:         objectId,
-			CreatedTime:      util.GetCurrentTime(),
-			SenderId:         senderId,
-			ReceiverId:       receiverId,
-			Status:           1,
+	watcherMap := make(map[string]bool)
+	for _, v := range GetTopicWatchMembers(topicId, 1) {
+		watcherMap[v] = true
+	}
+	// topics created before watch levels existed have no watch record of their author.
+	if GetTopicWatchLevel(author, topicId, author) == 1 {
+		watcherMap[author] = true
+	}
+	delete(watcherMap, senderId)
+
+	memberMap := getMentionedMembers(senderId, content)
+	for k := range memberMap {
+		if watcherMap[k] || GetTopicWatchLevel(k, topicId, author) == 3 {
+			delete(memberMap, k)
 		}
-		_ = AddNotification(&notification)
-		// send remind email
-		reminder, email := GetMemberEmailReminder(receiverId)
-		if email != "" && reminder {
-			topicIdStr := util.IntToString(topicId)
-			err := service.SendRemindMail(topicInfo.Title, content, topicIdStr, email, Domain)
-			if err != nil {
-				panic(err)
+	}
+	deleteBlockers(watcherMap, senderId)
+	deleteBlockers(memberMap, senderId)
+
+	var wg sync.WaitGroup
+
+	for k := range watcherMap {
+		wg.Add(1)
+		k := k
+		go func() {
+			defer wg.Done()
+			notification := Notification{
+				NotificationType: 1,
+				ObjectId:         objectId,
+				CreatedTime:      util.GetCurrentTime(),
+				SenderId:         senderId,
+				ReceiverId:       k,
+				Status:           1,
 			}
-		}
+			_ = AddNotification(&notification)
+			sendNotificationMail(k, notification.NotificationType, topicInfo.Title, content, topicId)
+		}()
 	}
 
-	delete(memberMap, receiverId)
-	for k, _ := range memberMap {
+	for k := range memberMap {
 		wg.Add(1)
 		k := k
 		go func() {
@@ -232,15 +319,7 @@ func AddReplyNotification(senderId, content string, objectId, topicId int) {
 				Status:           1,
 			}
 			_ = AddNotification(&notification)
-			// send remind email
-			reminder, email := GetMemberEmailReminder(receiverId)
-			if email != "" && reminder {
-				topicIdStr := util.IntToString(topicId)
-				err := service.SendRemindMail(topicInfo.Title, content, topicIdStr, email, Domain)
-				if err != nil {
-					panic(err)
-				}
-			}
+			sendNotificationMail(k, notification.NotificationType, topicInfo.Title, content, topicId)
 		}()
 	}
 	wg.Wait()
@@ -248,26 +327,10 @@ func AddReplyNotification(senderId, content string, objectId, topicId int) {
 
 func AddTopicNotification(objectId int, author, content string) {
 	var wg sync.WaitGroup
-	memberMap := make(map[string]bool)
-	reg := regexp.MustCompile("@(.*?)[ \n\t]")
-	reg2 := regexp.MustCompile("@([^ \n\t]*?)[^ \n\t]$")
-	regResult := reg.FindAllStringSubmatch(content, -1)
-	regResult2 := reg2.FindAllStringSubmatch(content, -1)
-
-	for _, v := range regResult {
-		if author != v[1] && !memberMap[v[1]] {
-			memberMap[v[1]] = true
-		}
-	}
-
-	for _, v := range regResult2 {
-		v[1] += content[len(content)-1:]
-		if author != v[1] && !memberMap[v[1]] {
-			memberMap[v[1]] = true
-		}
-	}
+	memberMap := getMentionedMembers(author, content)
+	deleteBlockers(memberMap, author)
 
-	for k, _ := range memberMap {
+	for k := range memberMap {
 		wg.Add(1)
 		k := k
 		go func() {
@@ -281,16 +344,68 @@ func AddTopicNotification(objectId int, author, content string) {
 				Status:           1,
 			}
 			_ = AddNotification(&notification)
-			// send remind email
-			reminder, email := GetMemberEmailReminder(k)
-			if email != "" && reminder {
-				topicIdStr := util.IntToString(objectId)
-				err := service.SendRemindMail(GetTopicTitle(objectId), content, topicIdStr, email, Domain)
-				if err != nil {
-					panic(err)
-				}
-			}
+			sendNotificationMail(k, notification.NotificationType, GetTopicTitle(objectId), content, objectId)
 		}()
 	}
 	wg.Wait()
 }
+
+// AddFavoriteNotification notifies the author that the topic has been added to favorites.
+func AddFavoriteNotification(senderId string, topicId int) {
+	topic := GetTopicBasicInfo(topicId)
+	if topic == nil || topic.Author == senderId || IsMemberBlocked(topic.Author, senderId) {
+		return
+	}
+
+	notification := Notification{
+		NotificationType: 4,
+		ObjectId:         topicId,
+		CreatedTime:      util.GetCurrentTime(),
+		SenderId:         senderId,
+		ReceiverId:       topic.Author,
+		Status:           1,
+	}
+	_ = AddNotification(&notification)
+	sendNotificationMail(topic.Author, notification.NotificationType, topic.Title, senderId+" added your topic to favorites.", topicId)
+}
+
+// AddFollowNotification notifies the member of the new follower.
+func AddFollowNotification(senderId, memberId string) {
+	if senderId == memberId || !HasMember(memberId) || IsMemberBlocked(memberId, senderId) {
+		return
+	}
+
+	notification := Notification{
+		NotificationType: 10,
+		CreatedTime:      util.GetCurrentTime(),
+		SenderId:         senderId,
+		ReceiverId:       memberId,
+		Status:           1,
+	}
+	_ = AddNotification(&notification)
+	sendNotificationMail(memberId, notification.NotificationType, senderId+" followed you", senderId+" followed you.", 0)
+}
+
+// AddThanksNotification notifies the author of the thanks, objectId is the topic or the reply.
+func AddThanksNotification(senderId, author string, objectId int, isReply bool) {
+	if IsMemberBlocked(author, senderId) {
+		return
+	}
+
+	notification := Notification{
+		NotificationType: 5,
+		ObjectId:         objectId,
+		CreatedTime:      util.GetCurrentTime(),
+		SenderId:         senderId,
+		ReceiverId:       author,
+		Status:           1,
+	}
+	topicId := objectId
+	if isReply {
+		notification.NotificationType = 6
+		topicId = GetReply(objectId).TopicId
+	}
+
+	_ = AddNotification(&notification)
+	sendNotificationMail(author, notification.NotificationType, GetTopicTitle(topicId), senderId+" thanked you.", topicId)
+}
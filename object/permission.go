@@ -0,0 +1,355 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"xorm.io/xorm"
+)
+
+// Roles are granted to members in a domain: the whole site, a node or a tab. A role in the site domain
+// applies to every node and tab.
+const (
+	RoleAdmin     = "admin"     // site only
+	RoleModerator = "moderator" // site, node or tab
+	RoleTrusted   = "trusted"   // site only
+)
+
+// Permissions checked by the controllers.
+const (
+	PermissionManage         = "manage"          // site settings, nodes, tabs, planes, members and roles
+	PermissionModerate       = "moderate"        // topics, replies and files of others
+	PermissionPostRestricted = "post-restricted" // posting in the nodes regardless of their minimal account age
+)
+
+const (
+	DomainSite       = "site"
+	nodeDomainPrefix = "node:"
+	tabDomainPrefix  = "tab:"
+	// roles are stored with a prefix, so that they never collide with member ids.
+	roleSubjectPrefix = "role:"
+)
+
+const permissionModelText = `
+[request_definition]
+r = sub, dom, act
+
+[policy_definition]
+p = sub, dom, act
+
+[role_definition]
+g = _, _, _
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = (g(r.sub, p.sub, r.dom) || g(r.sub, p.sub, "site")) && (p.dom == "*" || p.dom == r.dom) && (p.act == "*" || p.act == r.act)
+`
+
+// defaultRolePolicies are the permissions of the roles, the missing ones are added on startup.
+var defaultRolePolicies = [][]string{
+	{RoleAdmin, "*", "*"},
+	{RoleModerator, "*", PermissionModerate},
+	{RoleModerator, "*", PermissionPostRestricted},
+	{RoleTrusted, "*", PermissionPostRestricted},
+}
+
+// elevatedPermissions are only available with two-factor authentication if it's required for moderators.
+var elevatedPermissions = map[string]bool{
+	PermissionManage:   true,
+	PermissionModerate: true,
+}
+
+// MemberRole is a role of the member in the domain.
+type MemberRole struct {
+	MemberId string `json:"memberId"`
+	Role     string `json:"role"`
+	Domain   string `json:"domain"`
+}
+
+var enforcer *casbin.SyncedEnforcer
+
+// IsElevatedPermission returns whether the permission is for admins and moderators.
+func IsElevatedPermission(permission string) bool {
+	return elevatedPermissions[permission]
+}
+
+func initEnforcer() {
+	m, err := model.NewModelFromString(permissionModelText)
+	if err != nil {
+		panic(err)
+	}
+
+	enforcer, err = casbin.NewSyncedEnforcer(m, &casbinAdapter{engine: adapter.engine})
+	if err != nil {
+		panic(err)
+	}
+
+	for _, policy := range defaultRolePolicies {
+		_, err = enforcer.AddPolicy(getRoleSubject(policy[0]), policy[1], policy[2])
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func getRoleSubject(role string) string {
+	return roleSubjectPrefix + role
+}
+
+func GetNodeDomain(nodeId string) string {
+	return nodeDomainPrefix + nodeId
+}
+
+func GetTabDomain(tabId string) string {
+	return tabDomainPrefix + tabId
+}
+
+func getNodeTabId(nodeId string) string {
+	node := Node{Id: nodeId}
+	existed, err := adapter.engine.Cols("tab_id").Get(&node)
+	if err != nil {
+		panic(err)
+	}
+
+	if !existed {
+		return ""
+	}
+	return node.TabId
+}
+
+// IsValidMemberRole returns whether the role could be granted in the domain.
+func IsValidMemberRole(role, domain string) bool {
+	switch role {
+	case RoleAdmin, RoleTrusted:
+		return domain == DomainSite
+	case RoleModerator:
+		if domain == DomainSite {
+			return true
+		}
+		if strings.HasPrefix(domain, nodeDomainPrefix) {
+			return HasNode(strings.TrimPrefix(domain, nodeDomainPrefix))
+		}
+		if strings.HasPrefix(domain, tabDomainPrefix) {
+			return HasTab(strings.TrimPrefix(domain, tabDomainPrefix))
+		}
+	}
+	return false
+}
+
+func enforce(memberId, domain, permission string) bool {
+	allowed, err := enforcer.Enforce(memberId, domain, permission)
+	if err != nil {
+		panic(err)
+	}
+
+	return allowed
+}
+
+// CheckPermission returns whether the member has the permission in the domain. The roles in a node's tab
+// also apply to the node, and the elevated permissions follow the two-factor requirement of moderators.
+func CheckPermission(memberId, domain, permission string) bool {
+	if memberId == "" {
+		return false
+	}
+
+	allowed := enforce(memberId, domain, permission)
+	if !allowed && strings.HasPrefix(domain, nodeDomainPrefix) {
+		tabId := getNodeTabId(strings.TrimPrefix(domain, nodeDomainPrefix))
+		allowed = tabId != "" && enforce(memberId, GetTabDomain(tabId), permission)
+	}
+
+	if allowed && IsElevatedPermission(permission) {
+		return twoFactorSatisfied(memberId)
+	}
+	return allowed
+}
+
+// HasMemberRole returns whether the member has the role in the domain, ignoring the two-factor requirement.
+func HasMemberRole(memberId, role, domain string) bool {
+	has, err := enforcer.HasGroupingPolicy(memberId, getRoleSubject(role), domain)
+	if err != nil {
+		panic(err)
+	}
+
+	return has
+}
+
+func GetMemberRoles(memberId string) []*MemberRole {
+	rules, err := enforcer.GetFilteredGroupingPolicy(0, memberId)
+	if err != nil {
+		panic(err)
+	}
+
+	roles := []*MemberRole{}
+	for _, rule := range rules {
+		roles = append(roles, &MemberRole{MemberId: rule[0], Role: strings.TrimPrefix(rule[1], roleSubjectPrefix), Domain: rule[2]})
+	}
+	return roles
+}
+
+// GetRoleMembers returns the members with the role in the domain, or in all the domains if domain is empty.
+func GetRoleMembers(role, domain string) []*MemberRole {
+	rules, err := enforcer.GetFilteredGroupingPolicy(1, getRoleSubject(role), domain)
+	if err != nil {
+		panic(err)
+	}
+
+	roles := []*MemberRole{}
+	for _, rule := range rules {
+		roles = append(roles, &MemberRole{MemberId: rule[0], Role: role, Domain: rule[2]})
+	}
+	return roles
+}
+
+func AddMemberRole(memberId, role, domain string) bool {
+	added, err := enforcer.AddGroupingPolicy(memberId, getRoleSubject(role), domain)
+	if err != nil {
+		panic(err)
+	}
+
+	return added
+}
+
+func DeleteMemberRole(memberId, role, domain string) bool {
+	removed, err := enforcer.RemoveGroupingPolicy(memberId, getRoleSubject(role), domain)
+	if err != nil {
+		panic(err)
+	}
+
+	return removed
+}
+
+// DeleteMemberRoles removes all the roles of the member.
+func DeleteMemberRoles(memberId string) bool {
+	removed, err := enforcer.RemoveFilteredGroupingPolicy(0, memberId)
+	if err != nil {
+		panic(err)
+	}
+
+	return removed
+}
+
+// deleteDomainRoles removes all the roles granted in the domain, e.g. of a deleted node.
+func deleteDomainRoles(domain string) {
+	_, err := enforcer.RemoveFilteredGroupingPolicy(2, domain)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// renameMemberRoles moves the roles to the new member id in the transaction, the enforcer must be
+// reloaded by reloadPermissions() after it's committed.
+func renameMemberRoles(session *xorm.Session, oldId, newId string) error {
+	_, err := session.Exec("update casbin_rule set v0 = ? where ptype = ? and v0 = ?", newId, "g", oldId)
+	return err
+}
+
+func reloadPermissions() {
+	err := enforcer.LoadPolicy()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// HasModeratorRight returns whether the member is an admin or a moderator of any domain.
+func HasModeratorRight(memberId string) bool {
+	for _, role := range GetMemberRoles(memberId) {
+		if role.Role == RoleAdmin || role.Role == RoleModerator {
+			return true
+		}
+	}
+	return false
+}
+
+// migratePermissions converts the legacy member.is_moderator and node.moderators columns to roles.
+func (a *Adapter) migratePermissions() {
+	hasModeratorFlag, err := a.engine.Dialect().IsColumnExist("member", "is_moderator")
+	if err != nil {
+		panic(err)
+	}
+	hasNodeModerators, err := a.engine.Dialect().IsColumnExist("node", "moderators")
+	if err != nil {
+		panic(err)
+	}
+	if !hasModeratorFlag && !hasNodeModerators {
+		return
+	}
+
+	_, err = a.engine.Transaction(func(session *xorm.Session) (interface{}, error) {
+		rules := []*CasbinRule{}
+		if hasModeratorFlag {
+			rows, err := session.QueryString("select id from member where is_moderator = 1")
+			if err != nil {
+				return nil, err
+			}
+			for _, row := range rows {
+				rules = append(rules, newCasbinRule("g", []string{row["id"], getRoleSubject(RoleAdmin), DomainSite}))
+			}
+		}
+		if hasNodeModerators {
+			rows, err := session.QueryString("select id, moderators from node where moderators <> ''")
+			if err != nil {
+				return nil, err
+			}
+			for _, row := range rows {
+				var moderators []string
+				// the lists which had overflowed the column can't be recovered
+				if json.Unmarshal([]byte(row["moderators"]), &moderators) != nil {
+					continue
+				}
+				for _, moderator := range moderators {
+					rules = append(rules, newCasbinRule("g", []string{moderator, getRoleSubject(RoleModerator), GetNodeDomain(row["id"])}))
+				}
+			}
+		}
+
+		for _, rule := range rules {
+			existed, err := session.Exist(rule)
+			if err != nil {
+				return nil, err
+			}
+			if existed {
+				continue
+			}
+			_, err = session.Insert(rule)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	if hasModeratorFlag {
+		_, err = a.engine.Exec("alter table member drop column is_moderator")
+		if err != nil {
+			panic(err)
+		}
+	}
+	if hasNodeModerators {
+		_, err = a.engine.Exec("alter table node drop column moderators")
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -52,11 +52,19 @@ func GetReplies(topicId int, memberId string, limit int, offset int) []*ReplyWit
 		panic(err)
 	}
 
-	isModerator := CheckModIdentity(memberId)
+	nodeId := GetTopicNodeId(topicId)
+	isModerator := CheckPermission(memberId, GetNodeDomain(nodeId), PermissionModerate)
+	policy := GetEffectiveNodePolicy(nodeId)
+	blockedMap := make(map[string]bool)
+	for _, id := range GetBlockedMemberIds(memberId) {
+		blockedMap[id] = true
+	}
 	for _, v := range replies {
+		// the replies of blocked members are collapsed by the frontend
+		v.Blocked = blockedMap[v.Author]
 		v.ThanksStatus = v.ConsumptionAmount != 0
-		v.Deletable = isModerator || ReplyDeletable(v.CreatedTime, memberId, v.Author)
-		v.Editable = isModerator || GetReplyEditableStatus(memberId, v.Author, v.CreatedTime)
+		v.Deletable = isModerator || ReplyDeletable(v.CreatedTime, memberId, v.Author, policy.ReplyDeletableTime)
+		v.Editable = isModerator || GetReplyEditableStatus(memberId, v.Author, v.CreatedTime, policy.ReplyEditableTime)
 	}
 
 	return replies
@@ -124,11 +132,13 @@ func GetReplyWithDetails(memberId string, id int) *ReplyWithAvatar {
 		panic(err)
 	}
 
-	isModerator := CheckModIdentity(memberId)
 	if existed {
+		nodeId := GetTopicNodeId(reply.TopicId)
+		isModerator := CheckPermission(memberId, GetNodeDomain(nodeId), PermissionModerate)
+		policy := GetEffectiveNodePolicy(nodeId)
 		reply.ThanksStatus = reply.ConsumptionAmount != 0
-		reply.Deletable = isModerator || ReplyDeletable(reply.CreatedTime, memberId, reply.Author)
-		reply.Editable = isModerator || GetReplyEditableStatus(memberId, reply.Author, reply.CreatedTime)
+		reply.Deletable = isModerator || ReplyDeletable(reply.CreatedTime, memberId, reply.Author, policy.ReplyDeletableTime)
+		reply.Editable = isModerator || GetReplyEditableStatus(memberId, reply.Author, reply.CreatedTime, policy.ReplyEditableTime)
 		return &reply
 	}
 	return nil
@@ -187,6 +197,9 @@ func AddReply(reply *Reply) (bool, int) {
 		panic(err)
 	}
 
+	if affected != 0 {
+		publishReply(reply)
+	}
 	return affected != 0, reply.Id
 }
 
@@ -287,7 +300,8 @@ func AddReplyThanksNum(id int) bool {
 }
 
 // ReplyDeletable checks whether the reply can be deleted.
-func ReplyDeletable(date, memberId, author string) bool {
+// deletableTime is the effective reply deletable time of the topic's node, in minutes.
+func ReplyDeletable(date, memberId, author string, deletableTime float64) bool {
 	if memberId != author {
 		return false
 	}
@@ -300,7 +314,7 @@ func ReplyDeletable(date, memberId, author string) bool {
 	t = t.Add(8 * h)
 
 	now := time.Now()
-	if now.Sub(t).Minutes() > ReplyDeletableTime {
+	if now.Sub(t).Minutes() > deletableTime {
 		return false
 	}
 
@@ -308,7 +322,8 @@ func ReplyDeletable(date, memberId, author string) bool {
 }
 
 // GetReplyEditableStatus checks whether the reply can be edited.
-func GetReplyEditableStatus(member, author, createdTime string) bool {
+// editableTime is the effective reply editable time of the topic's node, in minutes.
+func GetReplyEditableStatus(member, author, createdTime string, editableTime float64) bool {
 	if member != author {
 		return false
 	}
@@ -321,7 +336,7 @@ func GetReplyEditableStatus(member, author, createdTime string) bool {
 	t = t.Add(8 * h)
 
 	now := time.Now()
-	if now.Sub(t).Minutes() > ReplyEditableTime {
+	if now.Sub(t).Minutes() > editableTime {
 		return false
 	}
 
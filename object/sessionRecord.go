@@ -0,0 +1,159 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"github.com/casbin/casnode/util"
+)
+
+// SessionRecord is the metadata of a signed in session, the session itself is in the session table.
+// Id is the hash of the session key, so the key is never sent to the client.
+type SessionRecord struct {
+	Id           string `xorm:"varchar(100) notnull pk" json:"id"`
+	SessionKey   string `xorm:"char(64) index" json:"-"`
+	MemberId     string `xorm:"varchar(100) index" json:"memberId"`
+	Ip           string `xorm:"varchar(100)" json:"ip"`
+	UserAgent    string `xorm:"varchar(500)" json:"userAgent"`
+	CreatedTime  string `xorm:"varchar(40)" json:"createdTime"`
+	LastSeenTime string `xorm:"varchar(40)" json:"lastSeenTime"`
+
+	Current bool `xorm:"-" json:"current"`
+}
+
+func GetSessionRecordId(sessionKey string) string {
+	return util.GetSha256Hash(sessionKey)
+}
+
+func AddSessionRecord(record *SessionRecord) bool {
+	record.Id = GetSessionRecordId(record.SessionKey)
+	if len(record.UserAgent) > 500 {
+		record.UserAgent = record.UserAgent[:500]
+	}
+
+	// the key could have been used by the member's previous sign in
+	_, err := adapter.engine.Id(record.Id).Delete(&SessionRecord{})
+	if err != nil {
+		panic(err)
+	}
+
+	affected, err := adapter.engine.Insert(record)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func GetMemberSessionRecords(memberId string) []*SessionRecord {
+	records := []*SessionRecord{}
+	err := adapter.engine.Where("member_id = ?", memberId).Desc("last_seen_time").Find(&records)
+	if err != nil {
+		panic(err)
+	}
+
+	return records
+}
+
+// TouchSessionRecord updates the last seen time at most once a minute, returns false if the session has no record.
+func TouchSessionRecord(sessionKey, memberId, ip string) bool {
+	id := GetSessionRecordId(sessionKey)
+	record := SessionRecord{Ip: ip, LastSeenTime: util.GetCurrentTime()}
+	affected, err := adapter.engine.Id(id).Where("member_id = ?", memberId).And("last_seen_time < ?", util.GetTimeMinute(-1)).
+		Cols("ip, last_seen_time").Update(&record)
+	if err != nil {
+		panic(err)
+	}
+	if affected != 0 {
+		return true
+	}
+
+	existed, err := adapter.engine.Id(id).Where("member_id = ?", memberId).Exist(&SessionRecord{})
+	if err != nil {
+		panic(err)
+	}
+
+	return existed
+}
+
+func deleteSessions(records []*SessionRecord) int {
+	num := 0
+	for _, record := range records {
+		_, err := adapter.engine.Where("session_key = ?", record.SessionKey).Delete(&Session{})
+		if err != nil {
+			panic(err)
+		}
+
+		affected, err := adapter.engine.Id(record.Id).Delete(&SessionRecord{})
+		if err != nil {
+			panic(err)
+		}
+		num += int(affected)
+	}
+
+	return num
+}
+
+// DeleteSessionRecord removes the record when the session signs out, the session itself is kept.
+func DeleteSessionRecord(sessionKey string) bool {
+	affected, err := adapter.engine.Id(GetSessionRecordId(sessionKey)).Delete(&SessionRecord{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// RevokeSession signs out the member's session by the record id.
+func RevokeSession(memberId, id string) bool {
+	records := []*SessionRecord{}
+	err := adapter.engine.Id(id).Where("member_id = ?", memberId).Find(&records)
+	if err != nil {
+		panic(err)
+	}
+
+	return deleteSessions(records) != 0
+}
+
+// RevokeMemberSessions signs out all the sessions of the member except the one with exceptKey,
+// and marks the sessions signed in before this feature, which have no record, as revoked.
+func RevokeMemberSessions(memberId, exceptKey string) int {
+	records := []*SessionRecord{}
+	err := adapter.engine.Where("member_id = ?", memberId).And("session_key <> ?", exceptKey).Find(&records)
+	if err != nil {
+		panic(err)
+	}
+
+	member := Member{SessionRevokedTime: util.GetCurrentTime()}
+	_, err = adapter.engine.Id(memberId).Cols("session_revoked_time").Update(&member)
+	if err != nil {
+		panic(err)
+	}
+
+	return deleteSessions(records)
+}
+
+// GetMemberSessionRevokedTime returns the last time all the sessions of the member were revoked.
+func GetMemberSessionRevokedTime(memberId string) string {
+	member := Member{}
+	existed, err := adapter.engine.Id(memberId).Cols("session_revoked_time").Get(&member)
+	if err != nil {
+		panic(err)
+	}
+	if !existed {
+		return ""
+	}
+
+	return member.SessionRevokedTime
+}
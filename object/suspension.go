@@ -0,0 +1,255 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"xorm.io/xorm"
+
+	"github.com/casbin/casnode/util"
+)
+
+// Suspension mutes the member (can't post topics or replies) in the whole site or only in NodeId,
+// or forbids the member from signing in. It's active until EndTime, or until it's lifted if EndTime is empty.
+// Member.Status follows the active site suspensions, see syncMemberStatus().
+type Suspension struct {
+	Id          int    `xorm:"int notnull pk autoincr" json:"id"`
+	MemberId    string `xorm:"varchar(100) index" json:"memberId"`
+	Type        string `xorm:"varchar(20)" json:"type"`
+	NodeId      string `xorm:"varchar(100)" json:"nodeId"` // empty means the whole site
+	Reason      string `xorm:"varchar(500)" json:"reason"`
+	Actor       string `xorm:"varchar(100)" json:"actor"`
+	CreatedTime string `xorm:"varchar(40)" json:"createdTime"`
+	EndTime     string `xorm:"varchar(40) index" json:"endTime"`
+	LiftedTime  string `xorm:"varchar(40) index" json:"liftedTime"`
+	LiftedBy    string `xorm:"varchar(100)" json:"liftedBy"` // empty if it's lifted by the cron job
+}
+
+const (
+	SuspensionMute   = "mute"
+	SuspensionForbid = "forbid"
+)
+
+// activeSuspensions returns the session of the member's active suspensions of the type.
+func activeSuspensions(memberId, suspensionType string) *xorm.Session {
+	return adapter.engine.Where("member_id = ?", memberId).And("type = ?", suspensionType).And("lifted_time = ?", "").
+		And("(end_time = ? or end_time > ?)", "", util.GetCurrentTime())
+}
+
+// IsMuted returns whether the member is muted in the whole site, or in the node if nodeId is given.
+func IsMuted(id, nodeId string) bool {
+	existed, err := activeSuspensions(id, SuspensionMute).And("(node_id = ? or node_id = ?)", "", nodeId).Exist(&Suspension{})
+	if err != nil {
+		panic(err)
+	}
+
+	return existed
+}
+
+// IsForbidden returns whether the member is forbidden from signing in, members that don't exist are forbidden too.
+func IsForbidden(id string) bool {
+	if !HasMember(id) {
+		return true
+	}
+
+	existed, err := activeSuspensions(id, SuspensionForbid).Exist(&Suspension{})
+	if err != nil {
+		panic(err)
+	}
+
+	return existed
+}
+
+func GetSuspension(id int) *Suspension {
+	suspension := Suspension{Id: id}
+	existed, err := adapter.engine.Get(&suspension)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &suspension
+	}
+	return nil
+}
+
+// IsActive returns whether the suspension is neither lifted nor expired.
+func (s *Suspension) IsActive() bool {
+	return s.LiftedTime == "" && (s.EndTime == "" || s.EndTime > util.GetCurrentTime())
+}
+
+// GetMemberSuspensions returns the suspension history of the member, the latest first.
+func GetMemberSuspensions(memberId string) []*Suspension {
+	suspensions := []*Suspension{}
+	err := adapter.engine.Where("member_id = ?", memberId).Desc("id").Find(&suspensions)
+	if err != nil {
+		panic(err)
+	}
+
+	return suspensions
+}
+
+// GetActiveSuspensions returns the active suspensions of all the members, the latest first.
+func GetActiveSuspensions(limit, offset int) []*Suspension {
+	suspensions := []*Suspension{}
+	err := adapter.engine.Where("lifted_time = ?", "").And("(end_time = ? or end_time > ?)", "", util.GetCurrentTime()).
+		Desc("id").Limit(limit, offset).Find(&suspensions)
+	if err != nil {
+		panic(err)
+	}
+
+	return suspensions
+}
+
+// syncMemberStatus updates Member.Status by the active site suspensions, members pending approval are kept.
+func syncMemberStatus(memberId string) {
+	status := 1
+	if IsForbidden(memberId) {
+		status = 3
+	} else if IsMuted(memberId, "") {
+		status = 2
+	}
+
+	member := Member{Status: status}
+	_, err := adapter.engine.Id(memberId).Where("status != ?", 4).Cols("status").Update(&member)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// addSuspensionNotification notifies the member of the suspension, 8 means suspended and 9 means lifted.
+// They are sent by the system, so that blocking the actor doesn't hide them.
+func addSuspensionNotification(suspension *Suspension, notificationType int) {
+	notification := Notification{
+		NotificationType: notificationType,
+		ObjectId:         suspension.Id,
+		CreatedTime:      util.GetCurrentTime(),
+		ReceiverId:       suspension.MemberId,
+		Status:           1,
+	}
+	_ = AddNotification(&notification)
+
+	title := "Your account has been suspended"
+	if notificationType == 9 {
+		title = "The suspension of your account has been lifted"
+	}
+	sendNotificationMail(suspension.MemberId, notificationType, title, title+". "+suspension.Reason, 0)
+}
+
+// AddSuspension suspends the member and notifies the member.
+func AddSuspension(suspension *Suspension) bool {
+	affected, err := adapter.engine.Insert(suspension)
+	if err != nil {
+		panic(err)
+	}
+	if affected == 0 {
+		return false
+	}
+
+	if suspension.NodeId == "" {
+		syncMemberStatus(suspension.MemberId)
+	}
+	if suspension.Type == SuspensionForbid {
+		RevokeMemberSessions(suspension.MemberId, "")
+	}
+	addSuspensionNotification(suspension, 8)
+	return true
+}
+
+// LiftSuspension lifts the active suspension and notifies the member, liftedBy is empty for the cron job.
+func LiftSuspension(id int, liftedBy string) bool {
+	suspension := Suspension{LiftedTime: util.GetCurrentTime(), LiftedBy: liftedBy}
+	affected, err := adapter.engine.Id(id).Where("lifted_time = ?", "").Cols("lifted_time, lifted_by").Update(&suspension)
+	if err != nil {
+		panic(err)
+	}
+	if affected == 0 {
+		return false
+	}
+
+	s := GetSuspension(id)
+	if s.NodeId == "" {
+		syncMemberStatus(s.MemberId)
+	}
+	addSuspensionNotification(s, 9)
+	return true
+}
+
+// LiftMemberSuspensions lifts the member's active suspensions in the whole site.
+func LiftMemberSuspensions(memberId, liftedBy string) int {
+	suspensions := []*Suspension{}
+	err := adapter.engine.Where("member_id = ?", memberId).And("node_id = ?", "").And("lifted_time = ?", "").Find(&suspensions)
+	if err != nil {
+		panic(err)
+	}
+
+	num := 0
+	for _, suspension := range suspensions {
+		if LiftSuspension(suspension.Id, liftedBy) {
+			num++
+		}
+	}
+	return num
+}
+
+// LiftExpiredSuspensions lifts the suspensions which have reached the end time, returns the number of them.
+func LiftExpiredSuspensions() int {
+	suspensions := []*Suspension{}
+	err := adapter.engine.Where("lifted_time = ?", "").And("end_time != ?", "").And("end_time <= ?", util.GetCurrentTime()).Find(&suspensions)
+	if err != nil {
+		panic(err)
+	}
+
+	num := 0
+	for _, suspension := range suspensions {
+		if LiftSuspension(suspension.Id, "") {
+			num++
+		}
+	}
+	return num
+}
+
+// migrateSuspensions adds permanent suspensions for the members muted or forbidden by the status before
+// suspensions existed, so that they stay suspended.
+func (a *Adapter) migrateSuspensions() {
+	count, err := a.engine.Count(&Suspension{})
+	if err != nil {
+		panic(err)
+	}
+	if count != 0 {
+		return
+	}
+
+	members := []*Member{}
+	err = a.engine.In("status", 2, 3).Cols("id, status").Find(&members)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, member := range members {
+		suspension := Suspension{
+			MemberId:    member.Id,
+			Type:        SuspensionMute,
+			CreatedTime: util.GetCurrentTime(),
+		}
+		if member.Status == 3 {
+			suspension.Type = SuspensionForbid
+		}
+
+		_, err = a.engine.Insert(&suspension)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
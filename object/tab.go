@@ -66,6 +66,9 @@ func DeleteTab(id string) bool {
 		panic(err)
 	}
 
+	if affected != 0 {
+		deleteDomainRoles(GetTabDomain(id))
+	}
 	return affected != 0
 }
 
@@ -15,6 +15,8 @@
 package object
 
 import (
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/casbin/casnode/util"
@@ -41,6 +43,9 @@ type Topic struct {
 	Deleted         bool     `xorm:"bool" json:"-"`
 	EditorType      string   `xorm:"varchar(40)" json:"editorType"`
 	Content         string   `xorm:"mediumtext" json:"content"`
+	AcceptedReplyId int      `xorm:"int index" json:"acceptedReplyId"`
+
+	Fields map[string]string `xorm:"-" json:"fields"`
 }
 
 func GetTopicCount() int {
@@ -71,10 +76,11 @@ func GetCreatedTopicsNum(memberId string) int {
 	return int(total)
 }
 
-func GetTopics(limit int, offset int) []*TopicWithAvatar {
+// GetTopics returns the topics on the home page, except the ones of the members blocked by memberId.
+func GetTopics(memberId string, limit int, offset int) []*TopicWithAvatar {
 	topics := []*TopicWithAvatar{}
-	err := adapter.engine.Table("topic").Join("LEFT OUTER", "member", "member.id = topic.author").
-		Where("topic.deleted = ?", 0).
+	err := excludeBlockedAuthors(adapter.engine.Table("topic"), memberId).Join("LEFT OUTER", "member", "member.id = topic.author").
+		And("topic.deleted = ?", 0).
 		Desc("topic.home_page_top_time").Desc("topic.last_reply_time").Desc("topic.created_time").
 		Cols("topic.id, topic.author, topic.node_id, topic.node_name, topic.title, topic.created_time, topic.last_reply_user, topic.last_Reply_time, topic.reply_count, topic.favorite_count, topic.deleted, topic.home_page_top_time, topic.tab_top_time, topic.node_top_time, member.avatar").
 		Limit(limit, offset).Find(&topics)
@@ -201,6 +207,7 @@ func GetTopicWithAvatar(id int, memberId string) *TopicWithAvatar {
 
 	topic.ThanksStatus = GetThanksStatus(memberId, id, 4)
 	topic.Editable = GetTopicEditableStatus(memberId, topic.Author, topic.NodeId, topic.CreatedTime)
+	topic.Fields = GetTopicFields(id)
 
 	return &topic
 }
@@ -292,10 +299,24 @@ func GetTopicNodeId(id int) string {
 	}
 }
 
-func GetTopicsWithNode(nodeId string, limit int, offset int) []*NodeTopic {
+// GetTopicsWithNode returns the topics of a node.
+// fields filters the topics by their structured values, e.g. {"os": "linux"}.
+func GetTopicsWithNode(nodeId, memberId string, fields map[string]string, limit int, offset int) []*NodeTopic {
 	topics := []*NodeTopic{}
-	err := adapter.engine.Table("topic").Join("LEFT OUTER", "member", "member.id = topic.author").
-		Where("topic.node_id = ?", nodeId).And("topic.deleted = ?", 0).
+	session := excludeBlockedAuthors(adapter.engine.Table("topic"), memberId).Join("LEFT OUTER", "member", "member.id = topic.author")
+
+	names := []string{}
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		alias := fmt.Sprintf("field%d", i)
+		session = session.Join("INNER", []string{"topic_field", alias},
+			alias+".topic_id = topic.id and "+alias+".name = ? and "+alias+".value = ?", name, fields[name])
+	}
+
+	err := session.And("topic.node_id = ?", nodeId).And("topic.deleted = ?", 0).
 		Desc("topic.node_top_time").Desc("topic.last_reply_time").Desc("topic.created_time").
 		Cols("topic.*, member.avatar").
 		Limit(limit, offset).Find(&topics)
@@ -460,14 +481,25 @@ func ChangeTopicLastReplyUser(topicId int, memberId string, updateTime string) b
 	return affected != 0
 }
 
-func GetTopicsWithTab(tab string, limit, offset int) []*TopicWithAvatar {
+// UpdateTopicAcceptedReply marks the reply as the answer of the topic, replyId 0 clears it.
+func UpdateTopicAcceptedReply(topicId int, replyId int) bool {
+	topic := Topic{AcceptedReplyId: replyId}
+	affected, err := adapter.engine.Id(topicId).Cols("accepted_reply_id").Update(&topic)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func GetTopicsWithTab(tab, memberId string, limit, offset int) []*TopicWithAvatar {
 	topics := []*TopicWithAvatar{}
 
 	if tab == "all" {
-		topics = GetTopics(limit, offset)
+		topics = GetTopics(memberId, limit, offset)
 	} else {
-		err := adapter.engine.Table("topic").Join("INNER", "node", "node.id = topic.node_id").Join("LEFT OUTER", "member", "member.id = topic.author").
-			Where("node.tab_id = ?", tab).And("topic.deleted = ?", 0).
+		err := excludeBlockedAuthors(adapter.engine.Table("topic"), memberId).Join("INNER", "node", "node.id = topic.node_id").Join("LEFT OUTER", "member", "member.id = topic.author").
+			And("node.tab_id = ?", tab).And("topic.deleted = ?", 0).
 			Desc("topic.tab_top_time").Desc("topic.last_reply_time").
 			Cols("topic.id, topic.author, topic.node_id, topic.node_name, topic.title, topic.created_time, topic.last_reply_user, topic.last_Reply_time, topic.reply_count, topic.favorite_count, topic.deleted, topic.home_page_top_time, topic.tab_top_time, topic.node_top_time, member.avatar").
 			Limit(limit, offset).Find(&topics)
@@ -504,7 +536,7 @@ func GetHotTopic(limit int) []*TopicWithAvatar {
 }
 
 func GetTopicEditableStatus(member, author, nodeId, createdTime string) bool {
-	if CheckModIdentity(member) || CheckNodeModerator(member, nodeId) {
+	if CheckPermission(member, GetNodeDomain(nodeId), PermissionModerate) {
 		return true
 	}
 	if member != author {
@@ -519,7 +551,7 @@ func GetTopicEditableStatus(member, author, nodeId, createdTime string) bool {
 	t = t.Add(8 * h)
 
 	now := time.Now()
-	if now.Sub(t).Minutes() > TopicEditableTime {
+	if now.Sub(t).Minutes() > GetEffectiveNodePolicy(nodeId).TopicEditableTime {
 		return false
 	}
 
@@ -0,0 +1,115 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "strings"
+
+// NodeTemplate is the topic template of a node: a prefilled body and the structured fields a topic may carry.
+type NodeTemplate struct {
+	Body   string           `json:"body"`
+	Fields []*TemplateField `json:"fields"`
+}
+
+type TemplateField struct {
+	Name     string `json:"name"`
+	Label    string `json:"label"`
+	Required bool   `json:"required"`
+}
+
+// TopicField stores one structured value of a topic, e.g. version=1.2.0 or os=linux.
+type TopicField struct {
+	Id      int    `xorm:"int notnull pk autoincr" json:"id"`
+	TopicId int    `xorm:"int index" json:"topicId"`
+	Name    string `xorm:"varchar(100) index(name_value)" json:"name"`
+	Value   string `xorm:"varchar(200) index(name_value)" json:"value"`
+}
+
+var MaxTopicFieldLength = 200
+
+// CheckTopicFields validates the fields against the template and returns the values defined by it.
+// The second return value is an error message, empty if the fields are valid.
+func (t *NodeTemplate) CheckTopicFields(fields map[string]string) (map[string]string, string) {
+	res := map[string]string{}
+	if t == nil {
+		return res, ""
+	}
+
+	for _, field := range t.Fields {
+		value := strings.TrimSpace(fields[field.Name])
+		if value == "" {
+			if field.Required {
+				return nil, "Field " + field.Label + " is required."
+			}
+			continue
+		}
+		if len(value) > MaxTopicFieldLength {
+			return nil, "Field " + field.Label + " is too long."
+		}
+		res[field.Name] = value
+	}
+
+	return res, ""
+}
+
+func GetNodeTemplate(nodeId string) *NodeTemplate {
+	if nodeId == "" {
+		return nil
+	}
+
+	node := Node{Id: nodeId}
+	existed, err := adapter.engine.Cols("template").Get(&node)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return node.Template
+	}
+	return nil
+}
+
+// AddTopicFields saves the structured values of a topic.
+func AddTopicFields(topicId int, fields map[string]string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+
+	records := []*TopicField{}
+	for name, value := range fields {
+		records = append(records, &TopicField{TopicId: topicId, Name: name, Value: value})
+	}
+
+	affected, err := adapter.engine.Insert(&records)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// GetTopicFields returns the structured values of a topic as a name-value map.
+func GetTopicFields(topicId int) map[string]string {
+	records := []*TopicField{}
+	err := adapter.engine.Where("topic_id = ?", topicId).Find(&records)
+	if err != nil {
+		panic(err)
+	}
+
+	res := map[string]string{}
+	for _, v := range records {
+		res[v.Name] = v.Value
+	}
+	return res
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "github.com/casbin/casnode/util"
+
+// TopicWatch records member's watch level of a topic.
+// WatchLevel 1-3 means: watching(notified of every reply), normal(notified of mentions only), muted(never notified, even mentioned).
+type TopicWatch struct {
+	Id          int    `xorm:"int notnull pk autoincr" json:"id"`
+	MemberId    string `xorm:"varchar(100) unique(member_topic)" json:"memberId"`
+	TopicId     int    `xorm:"int unique(member_topic) index" json:"topicId"`
+	WatchLevel  int    `xorm:"int" json:"watchLevel"`
+	CreatedTime string `xorm:"varchar(40)" json:"createdTime"`
+}
+
+// GetTopicWatch returns member's watch record of the topic, nil if member has never set it.
+func GetTopicWatch(memberId string, topicId int) *TopicWatch {
+	watch := TopicWatch{}
+	existed, err := adapter.engine.Where("member_id = ?", memberId).And("topic_id = ?", topicId).Get(&watch)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &watch
+	}
+	return nil
+}
+
+// GetTopicWatchLevel returns member's watch level of the topic.
+// Topic author is watching by default, other members are normal.
+func GetTopicWatchLevel(memberId string, topicId int, author string) int {
+	watch := GetTopicWatch(memberId, topicId)
+	if watch != nil {
+		return watch.WatchLevel
+	}
+
+	if memberId == author {
+		return 1
+	}
+	return 2
+}
+
+// UpdateTopicWatchLevel updates member's watch level of the topic, adds the record if not existed.
+func UpdateTopicWatchLevel(memberId string, topicId int, watchLevel int) bool {
+	if watchLevel < 1 || watchLevel > 3 {
+		return false
+	}
+
+	watch := GetTopicWatch(memberId, topicId)
+	if watch == nil {
+		watch = &TopicWatch{
+			MemberId:    memberId,
+			TopicId:     topicId,
+			WatchLevel:  watchLevel,
+			CreatedTime: util.GetCurrentTime(),
+		}
+		affected, err := adapter.engine.Insert(watch)
+		if err != nil {
+			panic(err)
+		}
+
+		return affected != 0
+	}
+
+	watch.WatchLevel = watchLevel
+	_, err := adapter.engine.Id(watch.Id).Cols("watch_level").Update(watch)
+	if err != nil {
+		panic(err)
+	}
+
+	return true
+}
+
+// AutoWatchTopic makes member watch the topic, unless member has already chosen a watch level.
+func AutoWatchTopic(memberId string, topicId int) bool {
+	if GetTopicWatch(memberId, topicId) != nil {
+		return false
+	}
+
+	return UpdateTopicWatchLevel(memberId, topicId, 1)
+}
+
+// GetTopicWatchMembers returns members whose watch level of the topic is watchLevel.
+func GetTopicWatchMembers(topicId int, watchLevel int) []string {
+	watches := []*TopicWatch{}
+	err := adapter.engine.Where("topic_id = ?", topicId).And("watch_level = ?", watchLevel).Cols("member_id").Find(&watches)
+	if err != nil {
+		panic(err)
+	}
+
+	res := []string{}
+	for _, v := range watches {
+		res = append(res, v.MemberId)
+	}
+
+	return res
+}
@@ -0,0 +1,195 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/casbin/casnode/util"
+)
+
+// TrustLevelRequirement is the activity a member needs for a trust level, see TrustLevelRequirements.
+type TrustLevelRequirement struct {
+	Days           int `json:"days"` // since the member signed up
+	Topics         int `json:"topics"`
+	Replies        int `json:"replies"`
+	ThanksReceived int `json:"thanksReceived"`
+}
+
+var linkRegex = regexp.MustCompile(`(?i)(https?://|www\.)\S`)
+
+func GetMaxTrustLevel() int {
+	return len(TrustLevelRequirements) - 1
+}
+
+func IsValidTrustLevel(level int) bool {
+	return level >= 0 && level <= GetMaxTrustLevel()
+}
+
+// GetThanksReceivedNum returns how many times the member's topics and replies have been thanked.
+func GetThanksReceivedNum(memberId string) int {
+	total, err := adapter.engine.Where("receiver_id = ?", memberId).In("consumption_type", 2, 3).Count(&ConsumptionRecord{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(total)
+}
+
+// ComputeTrustLevel returns the trust level earned by the member's activity. Members flagged by moderators
+// (muted, forbidden or pending approval) stay at level 0, and admins and moderators have the highest level.
+func ComputeTrustLevel(member *Member) int {
+	if member.Status > 1 {
+		return 0
+	}
+	if HasModeratorRight(member.Id) {
+		return GetMaxTrustLevel()
+	}
+
+	// the counts are only queried when a level needs them
+	topics, replies, thanks := -1, -1, -1
+	level := 0
+	for i := 1; i <= GetMaxTrustLevel(); i++ {
+		req := TrustLevelRequirements[i]
+		if member.CreatedTime > util.GetTimeDay(-req.Days) {
+			break
+		}
+		if req.Topics > 0 && topics < 0 {
+			topics = GetCreatedTopicsNum(member.Id)
+		}
+		if topics < req.Topics {
+			break
+		}
+		if req.Replies > 0 && replies < 0 {
+			replies = GetMemberRepliesNum(member.Id)
+		}
+		if replies < req.Replies {
+			break
+		}
+		if req.ThanksReceived > 0 && thanks < 0 {
+			thanks = GetThanksReceivedNum(member.Id)
+		}
+		if thanks < req.ThanksReceived {
+			break
+		}
+		level = i
+	}
+
+	return level
+}
+
+func updateMemberTrustLevel(id string, level int) bool {
+	member := Member{TrustLevel: level, TrustLevelTime: util.GetCurrentTime()}
+	affected, err := adapter.engine.Id(id).Cols("trust_level, trust_level_time").Update(&member)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// GetMemberTrustLevel returns the trust level of the member, it's computed right away if the cron job
+// hasn't done it yet.
+func GetMemberTrustLevel(id string) int {
+	member := Member{}
+	existed, err := adapter.engine.Id(id).Cols("id, created_time, status, trust_level, trust_level_pinned, trust_level_time").Get(&member)
+	if err != nil {
+		panic(err)
+	}
+	if !existed {
+		return 0
+	}
+
+	if member.TrustLevelPinned || member.TrustLevelTime != "" {
+		return member.TrustLevel
+	}
+
+	level := ComputeTrustLevel(&member)
+	updateMemberTrustLevel(id, level)
+	return level
+}
+
+// UpdateTrustLevels recomputes the trust levels of the members which aren't pinned, returns the number of changed ones.
+func UpdateTrustLevels() int {
+	members := []*Member{}
+	err := adapter.engine.Where("trust_level_pinned = ?", false).Cols("id, created_time, status, trust_level, trust_level_time").Find(&members)
+	if err != nil {
+		panic(err)
+	}
+
+	num := 0
+	for _, member := range members {
+		level := ComputeTrustLevel(member)
+		if level == member.TrustLevel && member.TrustLevelTime != "" {
+			continue
+		}
+		if updateMemberTrustLevel(member.Id, level) && level != member.TrustLevel {
+			num++
+		}
+	}
+
+	return num
+}
+
+// PinMemberTrustLevel sets the trust level of the member, which won't be recomputed until it's unpinned.
+func PinMemberTrustLevel(id string, level int) bool {
+	member := Member{TrustLevel: level, TrustLevelPinned: true, TrustLevelTime: util.GetCurrentTime()}
+	affected, err := adapter.engine.Id(id).Cols("trust_level, trust_level_pinned, trust_level_time").Update(&member)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// UnpinMemberTrustLevel lets the trust level of the member be computed again.
+func UnpinMemberTrustLevel(id string) bool {
+	member := Member{TrustLevelPinned: false, TrustLevelTime: ""}
+	affected, err := adapter.engine.Id(id).Cols("trust_level_pinned, trust_level_time").Update(&member)
+	if err != nil {
+		panic(err)
+	}
+
+	if affected != 0 {
+		GetMemberTrustLevel(id)
+	}
+	return affected != 0
+}
+
+// CheckTrustLevelContent returns the error message if the member's trust level doesn't allow
+// the links or the number of mentions in the content.
+func CheckTrustLevelContent(memberId string, content string) string {
+	level := GetMemberTrustLevel(memberId)
+	if level < PostLinkTrustLevel && linkRegex.MatchString(content) {
+		return fmt.Sprintf("You need trust level %d to post links.", PostLinkTrustLevel)
+	}
+
+	maxNum := MaxMentionNums[len(MaxMentionNums)-1]
+	if level < len(MaxMentionNums) {
+		maxNum = MaxMentionNums[level]
+	}
+	num := 0
+	for id := range getMentionedMembers(memberId, content) {
+		if HasMember(id) {
+			num++
+		}
+	}
+	if num > maxNum {
+		return fmt.Sprintf("You can mention at most %d members at your trust level.", maxNum)
+	}
+
+	return ""
+}
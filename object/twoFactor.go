@@ -0,0 +1,195 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/casbin/casnode/util"
+)
+
+// RecoveryCode is a one-time code to sign in when the authenticator is lost, only the hash is stored.
+type RecoveryCode struct {
+	Id          int    `xorm:"int notnull pk autoincr" json:"id"`
+	MemberId    string `xorm:"varchar(100) index" json:"memberId"`
+	CodeHash    string `xorm:"varchar(100)" json:"-"`
+	CreatedTime string `xorm:"varchar(40)" json:"createdTime"`
+}
+
+var RecoveryCodeNum = 10
+
+// SetMemberTotpSecret saves a new secret for the member, two-factor authentication isn't enabled until the first code is verified.
+func SetMemberTotpSecret(id, secret string) bool {
+	member := new(Member)
+	member.TotpSecret = secret
+	member.TotpEnabled = false
+	member.TotpLastStep = 0
+
+	affected, err := adapter.engine.Id(id).MustCols("totp_secret, totp_enabled, totp_last_step").Update(member)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func EnableMemberTotp(id string) bool {
+	member := new(Member)
+	member.TotpEnabled = true
+
+	affected, err := adapter.engine.Id(id).MustCols("totp_enabled").Update(member)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// DisableMemberTotp clears the secret and the recovery codes of the member.
+func DisableMemberTotp(id string) bool {
+	affected := SetMemberTotpSecret(id, "")
+	DeleteRecoveryCodes(id)
+
+	return affected
+}
+
+func GetMemberTotpEnabled(id string) bool {
+	member := Member{}
+	existed, err := adapter.engine.Id(id).Cols("totp_enabled").Get(&member)
+	if err != nil {
+		panic(err)
+	}
+
+	return existed && member.TotpEnabled
+}
+
+// VerifyMemberTotp checks the code against the member's secret, allowing one step of clock drift.
+// A code can only be used once.
+func VerifyMemberTotp(id, code string) bool {
+	member := Member{}
+	existed, err := adapter.engine.Id(id).Cols("totp_secret, totp_last_step").Get(&member)
+	if err != nil {
+		panic(err)
+	}
+	if !existed || member.TotpSecret == "" {
+		return false
+	}
+
+	code = strings.TrimSpace(code)
+	now := util.GetTotpStep(time.Now())
+	for step := now - 1; step <= now+1; step++ {
+		if step <= member.TotpLastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(util.GetTotpCode(member.TotpSecret, step)), []byte(code)) == 1 {
+			// only one request can consume the step
+			affected, err := adapter.engine.Table(new(Member)).Where("id = ?", id).And("totp_last_step < ?", step).
+				Update(map[string]interface{}{"totp_last_step": step})
+			if err != nil {
+				panic(err)
+			}
+			return affected != 0
+		}
+	}
+	return false
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+}
+
+// GenerateRecoveryCodes replaces the recovery codes of the member and returns the new codes.
+func GenerateRecoveryCodes(memberId string) []string {
+	DeleteRecoveryCodes(memberId)
+
+	codes := []string{}
+	records := []*RecoveryCode{}
+	for i := 0; i < RecoveryCodeNum; i++ {
+		code := util.GenerateRecoveryCode()
+		codes = append(codes, code)
+		records = append(records, &RecoveryCode{
+			MemberId:    memberId,
+			CodeHash:    util.GetSha256Hash(code),
+			CreatedTime: util.GetCurrentTime(),
+		})
+	}
+
+	_, err := adapter.engine.Insert(&records)
+	if err != nil {
+		panic(err)
+	}
+
+	return codes
+}
+
+// UseRecoveryCode consumes the recovery code, returns false if it's not a valid code of the member.
+func UseRecoveryCode(memberId, code string) bool {
+	hash := util.GetSha256Hash(normalizeRecoveryCode(code))
+	affected, err := adapter.engine.Where("member_id = ?", memberId).And("code_hash = ?", hash).Delete(&RecoveryCode{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func GetRecoveryCodeNum(memberId string) int {
+	total, err := adapter.engine.Where("member_id = ?", memberId).Count(&RecoveryCode{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(total)
+}
+
+func DeleteRecoveryCodes(memberId string) bool {
+	affected, err := adapter.engine.Where("member_id = ?", memberId).Delete(&RecoveryCode{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// VerifyMemberTwoFactor accepts either a code from the authenticator or a recovery code.
+func VerifyMemberTwoFactor(memberId, code, recoveryCode string) bool {
+	if code != "" {
+		return VerifyMemberTotp(memberId, code)
+	}
+	if recoveryCode != "" {
+		return UseRecoveryCode(memberId, recoveryCode)
+	}
+	return false
+}
+
+// GetRequireModeratorTwoFactor returns whether moderators must enable two-factor authentication to use their rights.
+func GetRequireModeratorTwoFactor() bool {
+	return getBasicInfoValue("RequireModeratorTwoFactor", "false") == "true"
+}
+
+func UpdateRequireModeratorTwoFactor(require bool) bool {
+	value := "false"
+	if require {
+		value = "true"
+	}
+	return setBasicInfoValue("RequireModeratorTwoFactor", value)
+}
+
+// twoFactorSatisfied checks the two-factor requirement of the moderator rights.
+func twoFactorSatisfied(memberId string) bool {
+	return !GetRequireModeratorTwoFactor() || GetMemberTotpEnabled(memberId)
+}
@@ -47,6 +47,7 @@ type ReplyWithAvatar struct {
 	ThanksStatus      bool   `json:"thanksStatus"`
 	Deletable         bool   `json:"deletable"`
 	Editable          bool   `json:"editable"`
+	Blocked           bool   `json:"blocked"`
 	ConsumptionAmount int    `xorm:"amount" json:"amount"`
 }
 
@@ -74,6 +75,39 @@ type NotificationResponse struct {
 	Avatar        string `json:"avatar"`
 }
 
+// PublicMember is the member shown to others, the contact fields are empty unless the member has opted in.
+type PublicMember struct {
+	Id             string                 `json:"id"`
+	No             int                    `json:"no"`
+	Avatar         string                 `json:"avatar"`
+	CreatedTime    string                 `json:"createdTime"`
+	Tagline        string                 `json:"tagline"`
+	Company        string                 `json:"company"`
+	CompanyTitle   string                 `json:"companyTitle"`
+	Bio            string                 `json:"bio"`
+	Website        string                 `json:"website"`
+	Location       string                 `json:"location"`
+	ScoreCount     int                    `json:"scoreCount"`
+	OnlineStatus   bool                   `json:"onlineStatus"`
+	TrustLevel     int                    `json:"trustLevel"`
+	Email          string                 `json:"email,omitempty"`
+	Phone          string                 `json:"phone,omitempty"`
+	AreaCode       string                 `json:"areaCode,omitempty"`
+	LinkedAccounts []*PublicLinkedAccount `json:"linkedAccounts,omitempty"`
+}
+
+type PublicLinkedAccount struct {
+	Provider string `json:"provider"`
+	Username string `json:"username"`
+}
+
+type MemberBadgeResponse struct {
+	*MemberBadge `xorm:"extends"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Icon         string `json:"icon"`
+}
+
 type NodeNavigationResponse struct {
 	*Tab
 	Nodes []*Node `json:"nodes"`
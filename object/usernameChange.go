@@ -0,0 +1,242 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+
+	"github.com/casbin/casnode/util"
+)
+
+// UsernameChange is a rename request of a member, the approved ones are the history of the member's names.
+// MemberId is always the current username of the member.
+type UsernameChange struct {
+	Id          int    `xorm:"int notnull pk autoincr" json:"id"`
+	MemberId    string `xorm:"varchar(100) index" json:"memberId"`
+	OldName     string `xorm:"varchar(100) index" json:"oldName"`
+	NewName     string `xorm:"varchar(100) index" json:"newName"`
+	Reason      string `xorm:"varchar(500)" json:"reason"`
+	State       string `xorm:"varchar(20) index" json:"state"`
+	CreatedTime string `xorm:"varchar(40)" json:"createdTime"`
+	HandledTime string `xorm:"varchar(40)" json:"handledTime"`
+	Handler     string `xorm:"varchar(100)" json:"handler"`
+}
+
+const (
+	UsernameChangePending  = "pending"
+	UsernameChangeApproved = "approved"
+	UsernameChangeRejected = "rejected"
+)
+
+// memberReferences are the columns holding usernames, updated by a rename.
+var memberReferences = []struct {
+	table, column, condition string
+}{
+	{"topic", "author", ""},
+	{"topic", "last_reply_user", ""},
+	{"reply", "author", ""},
+	{"notification", "sender_id", ""},
+	{"notification", "receiver_id", ""},
+	{"favorites", "member_id", ""},
+	{"favorites", "object_id", "favorites_type = 2"},
+	{"member_block", "member_id", ""},
+	{"member_block", "blocked_id", ""},
+	{"invite_code", "creator_id", ""},
+	{"invite_code", "used_by", ""},
+	{"consumption_record", "consumer_id", ""},
+	{"consumption_record", "receiver_id", ""},
+	{"browse_record", "member_id", ""},
+	{"reset_record", "member_id", ""},
+	{"upload_file_record", "member_id", ""},
+	{"topic_watch", "member_id", ""},
+	{"linked_account", "member_id", ""},
+	{"recovery_code", "member_id", ""},
+	{"access_token", "member_id", ""},
+	{"session_record", "member_id", ""},
+	{"username_change", "member_id", ""},
+	{"member_badge", "member_id", ""},
+	{"member_badge", "granted_by", ""},
+	{"suspension", "member_id", ""},
+	{"suspension", "actor", ""},
+	{"suspension", "lifted_by", ""},
+	{"notification_preference", "member_id", ""},
+	{"member", "id", ""},
+}
+
+func AddUsernameChange(change *UsernameChange) bool {
+	affected, err := adapter.engine.Insert(change)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func GetUsernameChange(id int) *UsernameChange {
+	change := UsernameChange{Id: id}
+	existed, err := adapter.engine.Get(&change)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &change
+	}
+	return nil
+}
+
+// GetUsernameChanges returns the requests in the state, all the requests if the state is empty.
+func GetUsernameChanges(state string, limit, offset int) []*UsernameChange {
+	changes := []*UsernameChange{}
+	session := adapter.engine.Desc("id").Limit(limit, offset)
+	if state != "" {
+		session = session.Where("state = ?", state)
+	}
+	err := session.Find(&changes)
+	if err != nil {
+		panic(err)
+	}
+
+	return changes
+}
+
+func GetMemberUsernameChanges(memberId string) []*UsernameChange {
+	changes := []*UsernameChange{}
+	err := adapter.engine.Where("member_id = ?", memberId).Desc("id").Find(&changes)
+	if err != nil {
+		panic(err)
+	}
+
+	return changes
+}
+
+// CheckUsernameChangeAllowed returns the reason why the member can't request a rename now, or "".
+func CheckUsernameChangeAllowed(memberId string) string {
+	existed, err := adapter.engine.Where("member_id = ?", memberId).And("state = ?", UsernameChangePending).Exist(&UsernameChange{})
+	if err != nil {
+		panic(err)
+	}
+	if existed {
+		return "You already have a pending username change"
+	}
+
+	existed, err = adapter.engine.Where("member_id = ?", memberId).And("state = ?", UsernameChangeApproved).
+		And("handled_time > ?", util.GetTimeDay(-UsernameChangeInterval)).Exist(&UsernameChange{})
+	if err != nil {
+		panic(err)
+	}
+	if existed {
+		return fmt.Sprintf("You can only change your username once every %d days", UsernameChangeInterval)
+	}
+
+	return ""
+}
+
+// IsUsernameTaken returns whether the name is used by another member, now or in the past.
+// Old names stay reserved so that the old profile urls and mentions keep resolving.
+func IsUsernameTaken(name, memberId string) bool {
+	if name == DeletedMemberId || (name != memberId && HasMember(name)) {
+		return true
+	}
+
+	existed, err := adapter.engine.Where("member_id <> ?", memberId).
+		And("((old_name = ? and state = ?) or (new_name = ? and state = ?))", name, UsernameChangeApproved, name, UsernameChangePending).
+		Exist(&UsernameChange{})
+	if err != nil {
+		panic(err)
+	}
+
+	return existed
+}
+
+// GetRenamedMemberId returns the current username of the member who used the name before, or "".
+func GetRenamedMemberId(name string) string {
+	change := UsernameChange{}
+	existed, err := adapter.engine.Where("old_name = ?", name).And("state = ?", UsernameChangeApproved).Desc("id").Get(&change)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return change.MemberId
+	}
+	return ""
+}
+
+// ResolveMemberId returns the current username for the name, which could be an old name of the member.
+func ResolveMemberId(name string) string {
+	if HasMember(name) {
+		return name
+	}
+
+	if id := GetRenamedMemberId(name); id != "" {
+		return id
+	}
+	return name
+}
+
+func RejectUsernameChange(id int, handler string) bool {
+	change := UsernameChange{State: UsernameChangeRejected, HandledTime: util.GetCurrentTime(), Handler: handler}
+	affected, err := adapter.engine.Id(id).Where("state = ?", UsernameChangePending).Cols("state, handled_time, handler").Update(&change)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// ApproveUsernameChange renames the member, updating all the references in one transaction.
+// All the sessions of the member are signed out, since they hold the old name.
+func ApproveUsernameChange(id int, handler string) (bool, string) {
+	change := GetUsernameChange(id)
+	if change == nil || change.State != UsernameChangePending {
+		return false, "The request has been handled"
+	}
+	if IsUsernameTaken(change.NewName, change.MemberId) || HasMember(change.NewName) {
+		return false, "The username has been taken"
+	}
+
+	oldName, newName := change.MemberId, change.NewName
+	_, err := adapter.engine.Transaction(func(session *xorm.Session) (interface{}, error) {
+		for _, ref := range memberReferences {
+			sql := fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ?", ref.table, ref.column, ref.column)
+			if ref.condition != "" {
+				sql += " AND " + ref.condition
+			}
+			_, err := session.Exec(sql, newName, oldName)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err := renameMemberRoles(session, oldName, newName)
+		if err != nil {
+			return nil, err
+		}
+
+		state := UsernameChange{State: UsernameChangeApproved, HandledTime: util.GetCurrentTime(), Handler: handler}
+		_, err = session.Id(id).Cols("state, handled_time, handler").Update(&state)
+		return nil, err
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	reloadPermissions()
+	RevokeMemberSessions(newName, "")
+	return true, ""
+}
@@ -28,10 +28,12 @@ type ValidateCode struct {
 	Information string `xorm:"varchar(100)" json:"information"`
 	CreatedTime string `xorm:"varchar(40)" json:"createdTime"`
 	Expired     bool   `xorm:"bool" json:"expired"`
+	Attempts    int    `xorm:"int" json:"attempts"`
+	Ip          string `xorm:"varchar(100)" json:"ip"`
 }
 
 // AddValidateCode: return validate code and validate code ID
-func GetNewValidateCode(information string) (string, string) {
+func GetNewValidateCode(information, ip string) (string, string) {
 	code := getRandomCode(6)
 
 	validateCode := ValidateCode{
@@ -40,6 +42,7 @@ func GetNewValidateCode(information string) (string, string) {
 		Information: information,
 		CreatedTime: util.GetCurrentTime(),
 		Expired:     false,
+		Ip:          ip,
 	}
 	affected, err := adapter.engine.Insert(validateCode)
 	if err != nil {
@@ -66,7 +69,37 @@ func CheckValidateCodeExpired(id string) bool {
 	return false
 }
 
-// VerifyValidateCode verifies validate code.
+// CheckValidateCodeQuota checks whether another code could be sent to the information from the ip, returns the error message.
+func CheckValidateCodeQuota(information, ip string) string {
+	total, err := adapter.engine.Where("information = ?", information).And("created_time > ?", util.GetTimeMinute(-ValidateCodeSendInterval)).Count(&ValidateCode{})
+	if err != nil {
+		panic(err)
+	}
+	if total != 0 {
+		return "Please wait a minute before requesting another code"
+	}
+
+	date := util.GetTimeDay(-1)
+	total, err = adapter.engine.Where("information = ?", information).And("created_time > ?", date).Count(&ValidateCode{})
+	if err != nil {
+		panic(err)
+	}
+	if int(total) >= ValidateCodeDestQuota {
+		return "Too many codes have been sent to this address today"
+	}
+
+	total, err = adapter.engine.Where("ip = ?", ip).And("created_time > ?", date).Count(&ValidateCode{})
+	if err != nil {
+		panic(err)
+	}
+	if int(total) >= ValidateCodeIpQuota {
+		return "Too many codes have been requested from your network today"
+	}
+
+	return ""
+}
+
+// VerifyValidateCode verifies validate code, the code expires after MaxValidateCodeAttempts wrong attempts.
 func VerifyValidateCode(id, validateCode, information string) bool {
 	var code ValidateCode
 	existed, err := adapter.engine.Id(id).Get(&code)
@@ -74,12 +107,26 @@ func VerifyValidateCode(id, validateCode, information string) bool {
 		panic(err)
 	}
 
-	if !existed || code.Expired || code.Code != validateCode || code.Information != information {
+	if !existed || code.Expired {
+		return false
+	}
+
+	if code.Code != validateCode || code.Information != information {
+		// the attempts are counted in the database, so that concurrent guesses can't exceed the limit
+		_, err = adapter.engine.Exec("UPDATE validate_code SET attempts = attempts + 1 WHERE id = ? AND expired = ?", id, false)
+		if err != nil {
+			panic(err)
+		}
+		_, err = adapter.engine.Exec("UPDATE validate_code SET expired = ? WHERE id = ? AND attempts >= ?", true, id, MaxValidateCodeAttempts)
+		if err != nil {
+			panic(err)
+		}
 		return false
 	}
 
+	// only one request can use the code
 	code.Expired = true
-	affected, err := adapter.engine.Id(id).Cols("expired").Update(code)
+	affected, err := adapter.engine.Id(id).Where("expired = ?", false).Cols("expired").Update(code)
 	if err != nil {
 		panic(err)
 	}
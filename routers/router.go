@@ -49,6 +49,8 @@ func initAPI() {
 	beego.Router("/api/get-topics-num", &controllers.APIController{}, "GET:GetTopicsNum")
 	beego.Router("/api/add-topic-hit-count", &controllers.APIController{}, "POST:AddTopicHitCount")
 	beego.Router("/api/get-hot-topic", &controllers.APIController{}, "GET:GetHotTopic")
+	beego.Router("/api/get-topic-watch-level", &controllers.APIController{}, "GET:GetTopicWatchLevel")
+	beego.Router("/api/update-topic-watch-level", &controllers.APIController{}, "POST:UpdateTopicWatchLevel")
 	beego.Router("/api/add-topic-browse-record", &controllers.APIController{}, "POST:AddTopicBrowseCount")
 	beego.Router("/api/update-topic-node", &controllers.APIController{}, "POST:UpdateTopicNode")
 	beego.Router("/api/edit-content", &controllers.APIController{}, "POST:EditContent")
@@ -67,7 +69,11 @@ func initAPI() {
 	beego.Router("/api/update-reply", &controllers.APIController{}, "POST:UpdateReply")
 	beego.Router("/api/add-reply", &controllers.APIController{}, "POST:AddReply")
 	beego.Router("/api/delete-reply", &controllers.APIController{}, "POST:DeleteReply")
+	beego.Router("/api/accept-reply", &controllers.APIController{}, "POST:AcceptReply")
+	beego.Router("/api/unaccept-reply", &controllers.APIController{}, "POST:UnacceptReply")
 	beego.Router("/api/get-latest-replies", &controllers.APIController{}, "GET:GetLatestReplies")
+	beego.Router("/api/get-member-activities", &controllers.APIController{}, "GET:GetMemberActivities")
+	beego.Router("/api/get-following-activities", &controllers.APIController{}, "GET:GetFollowingActivities")
 	beego.Router("/api/get-member-replies-num", &controllers.APIController{}, "GET:GetMemberRepliesNum")
 	beego.Router("/api/get-reply-with-details", &controllers.APIController{}, "GET:GetReplyWithDetails")
 
@@ -86,10 +92,15 @@ func initAPI() {
 	beego.Router("/api/update-member-editor-type", &controllers.APIController{}, "POST:UpdateMemberEditorType")
 	beego.Router("/api/get-member-editor-type", &controllers.APIController{}, "GET:GetMemberEditorType")
 	beego.Router("/api/update-member-email-reminder", &controllers.APIController{}, "POST:UpdateMemberEmailReminder")
+	beego.Router("/api/update-member-digest-frequency", &controllers.APIController{}, "POST:UpdateMemberDigestFrequency")
+	beego.Router("/api/update-member-auto-watch-reply", &controllers.APIController{}, "POST:UpdateMemberAutoWatchReply")
+	beego.Router("/api/get-trust-level", &controllers.APIController{}, "GET:GetTrustLevel")
+	beego.Router("/api/update-member-trust-level", &controllers.APIController{}, "POST:UpdateMemberTrustLevel") // just for admin.
 	beego.Router("/api/get-ranking-rich", &controllers.APIController{}, "GET:GetRankingRich")
 
 	beego.Router("/api/get-nodes", &controllers.APIController{}, "GET:GetNodes")
 	beego.Router("/api/get-node", &controllers.APIController{}, "GET:GetNode")
+	beego.Router("/api/get-node-policy", &controllers.APIController{}, "GET:GetNodePolicy")
 	beego.Router("/api/update-node", &controllers.APIController{}, "POST:UpdateNode") // Update node api just for admin.
 	beego.Router("/api/add-node", &controllers.APIController{}, "POST:AddNode")       // Add node api just for admin.
 	beego.Router("/api/delete-node", &controllers.APIController{}, "POST:DeleteNode") // Delete node api just for admin.
@@ -101,17 +112,67 @@ func initAPI() {
 	beego.Router("/api/add-node-browse-record", &controllers.APIController{}, "POST:AddNodeBrowseCount")
 	beego.Router("/api/add-node-moderators", &controllers.APIController{}, "POST:AddNodeModerators")
 	beego.Router("/api/delete-node-moderators", &controllers.APIController{}, "POST:DeleteNodeModerators")
+	beego.Router("/api/get-member-roles", &controllers.APIController{}, "GET:GetMemberRoles")      // just for admin.
+	beego.Router("/api/add-member-role", &controllers.APIController{}, "POST:AddMemberRole")       // just for admin.
+	beego.Router("/api/delete-member-role", &controllers.APIController{}, "POST:DeleteMemberRole") // just for admin.
+	beego.Router("/api/suspend-member", &controllers.APIController{}, "POST:SuspendMember")
+	beego.Router("/api/lift-suspension", &controllers.APIController{}, "POST:LiftSuspension")
+	beego.Router("/api/get-member-suspensions", &controllers.APIController{}, "GET:GetMemberSuspensions")
+	beego.Router("/api/get-active-suspensions", &controllers.APIController{}, "GET:GetActiveSuspensions")
+	beego.Router("/api/get-queued-mails", &controllers.APIController{}, "GET:GetQueuedMails")      // just for admin.
+	beego.Router("/api/retry-queued-mail", &controllers.APIController{}, "POST:RetryQueuedMail")   // just for admin.
+	beego.Router("/api/delete-queued-mail", &controllers.APIController{}, "POST:DeleteQueuedMail") // just for admin.
+	beego.Router("/api/get-badges", &controllers.APIController{}, "GET:GetBadges")
+	beego.Router("/api/add-badge", &controllers.APIController{}, "POST:AddBadge")       // just for admin.
+	beego.Router("/api/update-badge", &controllers.APIController{}, "POST:UpdateBadge") // just for admin.
+	beego.Router("/api/delete-badge", &controllers.APIController{}, "POST:DeleteBadge") // just for admin.
+	beego.Router("/api/grant-badge", &controllers.APIController{}, "POST:GrantBadge")   // just for admin.
+	beego.Router("/api/revoke-badge", &controllers.APIController{}, "POST:RevokeBadge") // just for admin.
+	beego.Router("/api/get-member-badges", &controllers.APIController{}, "GET:GetMemberBadges")
+	beego.Router("/api/update-member-badge-visibility", &controllers.APIController{}, "POST:UpdateMemberBadgeVisibility")
 	beego.Router("/api/get-nodes-admin", &controllers.APIController{}, "GET:GetNodesAdmin")
 
 	beego.Router("/api/signup", &controllers.APIController{}, "POST:Signup")
 	beego.Router("/api/signin", &controllers.APIController{}, "POST:Signin")
+	beego.Router("/api/get-signin-captcha-required", &controllers.APIController{}, "GET:GetSigninCaptchaRequired")
 	beego.Router("/api/signout", &controllers.APIController{}, "POST:Signout")
 	beego.Router("/api/get-account", &controllers.APIController{}, "GET:GetAccount")
-	beego.Router("/api/auth/google", &controllers.APIController{}, "GET:AuthGoogle")
-	beego.Router("/api/auth/github", &controllers.APIController{}, "GET:AuthGithub")
-	beego.Router("/api/auth/qq", &controllers.APIController{}, "GET:AuthQQ")
-	beego.Router("/api/auth/wechat", &controllers.APIController{}, "GET:AuthWeChat")
+	beego.Router("/api/auth/:provider", &controllers.APIController{}, "GET:Auth")
+	beego.Router("/api/get-auth-providers", &controllers.APIController{}, "GET:GetAuthProviders")
+	beego.Router("/api/get-linked-accounts", &controllers.APIController{}, "GET:GetLinkedAccounts")
+	beego.Router("/api/unlink-account", &controllers.APIController{}, "POST:UnlinkAccount")
+	beego.Router("/api/signin-two-factor", &controllers.APIController{}, "POST:SigninTwoFactor")
+	beego.Router("/api/get-two-factor-status", &controllers.APIController{}, "GET:GetTwoFactorStatus")
+	beego.Router("/api/setup-two-factor", &controllers.APIController{}, "POST:SetupTwoFactor")
+	beego.Router("/api/enable-two-factor", &controllers.APIController{}, "POST:EnableTwoFactor")
+	beego.Router("/api/disable-two-factor", &controllers.APIController{}, "POST:DisableTwoFactor")
+	beego.Router("/api/regenerate-recovery-codes", &controllers.APIController{}, "POST:RegenerateRecoveryCodes")
+	beego.Router("/api/get-require-moderator-two-factor", &controllers.APIController{}, "GET:GetRequireModeratorTwoFactor")
+	beego.Router("/api/update-require-moderator-two-factor", &controllers.APIController{}, "POST:UpdateRequireModeratorTwoFactor") // just for admin.
+	beego.Router("/api/get-access-tokens", &controllers.APIController{}, "GET:GetAccessTokens")
+	beego.Router("/api/add-access-token", &controllers.APIController{}, "POST:AddAccessToken")
+	beego.Router("/api/delete-access-token", &controllers.APIController{}, "POST:DeleteAccessToken")
+	beego.Router("/api/get-sessions", &controllers.APIController{}, "GET:GetSessions")
+	beego.Router("/api/delete-session", &controllers.APIController{}, "POST:DeleteSession")
+	beego.Router("/api/signout-everywhere", &controllers.APIController{}, "POST:SignoutEverywhere")
+	beego.Router("/api/export-account", &controllers.APIController{}, "GET:ExportAccount")
+	beego.Router("/api/delete-account", &controllers.APIController{}, "POST:DeleteAccount")
+	beego.Router("/api/request-username-change", &controllers.APIController{}, "POST:RequestUsernameChange")
+	beego.Router("/api/get-account-username-changes", &controllers.APIController{}, "GET:GetAccountUsernameChanges")
+	beego.Router("/api/get-username-changes", &controllers.APIController{}, "GET:GetUsernameChanges")        // just for admin.
+	beego.Router("/api/approve-username-change", &controllers.APIController{}, "POST:ApproveUsernameChange") // just for admin.
+	beego.Router("/api/reject-username-change", &controllers.APIController{}, "POST:RejectUsernameChange")   // just for admin.
+	beego.Router("/api/get-signup-mode", &controllers.APIController{}, "GET:GetSignupMode")
+	beego.Router("/api/update-signup-mode", &controllers.APIController{}, "POST:UpdateSignupMode") // just for admin.
+	beego.Router("/api/get-invite-codes", &controllers.APIController{}, "GET:GetInviteCodes")
+	beego.Router("/api/add-invite-code", &controllers.APIController{}, "POST:AddInviteCode")
+	beego.Router("/api/delete-invite-code", &controllers.APIController{}, "POST:DeleteInviteCode")
+	beego.Router("/api/get-pending-members", &controllers.APIController{}, "GET:GetPendingMembers")      // just for admin.
+	beego.Router("/api/handle-pending-member", &controllers.APIController{}, "POST:HandlePendingMember") // just for admin.
+
 	beego.Router("/api/reset-password", &controllers.APIController{}, "POST:ResetPassword")
+	beego.Router("/api/request-contact-change", &controllers.APIController{}, "POST:RequestContactChange")
+	beego.Router("/api/confirm-contact-change", &controllers.APIController{}, "POST:ConfirmContactChange")
 
 	beego.Router("/api/add-favorites", &controllers.APIController{}, "POST:AddFavorites")
 	beego.Router("/api/get-favorites", &controllers.APIController{}, "GET:GetFavorites")
@@ -119,6 +180,11 @@ func initAPI() {
 	beego.Router("/api/get-favorites-status", &controllers.APIController{}, "GET:GetFavoritesStatus")
 	beego.Router("/api/get-account-favorite-num", &controllers.APIController{}, "GET:GetAccountFavoriteNum")
 
+	beego.Router("/api/get-member-blocks", &controllers.APIController{}, "GET:GetMemberBlocks")
+	beego.Router("/api/get-member-block-status", &controllers.APIController{}, "GET:GetMemberBlockStatus")
+	beego.Router("/api/add-member-block", &controllers.APIController{}, "POST:AddMemberBlock")
+	beego.Router("/api/delete-member-block", &controllers.APIController{}, "POST:DeleteMemberBlock")
+
 	beego.Router("/api/get-tabs", &controllers.APIController{}, "GET:GetTabs")
 	beego.Router("/api/get-all-tabs", &controllers.APIController{}, "GET:GetAllTabs")
 	beego.Router("/api/get-tab-with-nodes", &controllers.APIController{}, "GET:GetTabWithNodes")
@@ -131,6 +197,9 @@ func initAPI() {
 	beego.Router("/api/get-notifications", &controllers.APIController{}, "GET:GetNotifications")
 	beego.Router("/api/delete-notifications", &controllers.APIController{}, "POST:DeleteNotification")
 	beego.Router("/api/get-unread-notification-num", &controllers.APIController{}, "GET:GetUnreadNotificationNum")
+	beego.Router("/api/get-events", &controllers.APIController{}, "GET:GetEvents")
+	beego.Router("/api/get-notification-preferences", &controllers.APIController{}, "GET:GetNotificationPreferences")
+	beego.Router("/api/update-notification-preferences", &controllers.APIController{}, "POST:UpdateNotificationPreferences")
 	beego.Router("/api/update-read-status", &controllers.APIController{}, "POST:UpdateReadStatus")
 
 	beego.Router("/api/get-plane", &controllers.APIController{}, "GET:GetPlane")
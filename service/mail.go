@@ -21,6 +21,15 @@ import (
 	"github.com/go-gomail/gomail"
 )
 
+// Mail is an outbound email, it's queued by object.EnqueueMail and delivered by SendMail.
+type Mail struct {
+	Email    string
+	Subject  string
+	HtmlBody string
+	TextBody string // optional plain text alternative
+	Secret   bool   // the body has a code or a link to the account, it isn't kept after sending
+}
+
 var mailConn = map[string]string{
 	"user": beego.AppConfig.String("mailUser"),
 	"pass": beego.AppConfig.String("mailPass"),
@@ -28,12 +37,8 @@ var mailConn = map[string]string{
 	"port": beego.AppConfig.String("mailPort"),
 }
 
-// SendResetPasswordMail sends mail with reset password information.
-func SendResetPasswordMail(email, memberId, url string) error {
-	port, _ := strconv.Atoi(mailConn["port"])
-
-	mail := gomail.NewMessage()
-
+// NewResetPasswordMail returns the mail with reset password information.
+func NewResetPasswordMail(email, memberId, url string) *Mail {
 	name := beego.AppConfig.String("appname")
 	body := `Hi: ` + memberId + `, <br/><br/> 我们的系统收到一个请求，
 说你希望通过电子邮件重新设置你在 ` + name + ` 的密码。你可以点击下面的链接开始重设密码：<br/><br/><a href="` + url + `">` + url + `</a><br/><br/>
@@ -41,61 +46,96 @@ func SendResetPasswordMail(email, memberId, url string) error {
 如果你有任何疑问，可以回复这封邮件向我们提问。<br/><br/>
 <front color="#888888">` + name + `</front>`
 
-	mail.SetHeader("From", mail.FormatAddress(mailConn["user"], name))
-	mail.SetHeader("To", email)
-	mail.SetHeader("Subject", "["+name+"]"+" 重设密码") // set subject
-	mail.SetBody("text/html", body)                 // set body
+	return &Mail{Email: email, Subject: "[" + name + "]" + " 重设密码", HtmlBody: body, Secret: true}
+}
 
-	d := gomail.NewDialer(mailConn["host"], port, mailConn["user"], mailConn["pass"])
+// NewRegistrationMail returns the mail with registration information.
+func NewRegistrationMail(email, validateCode string) *Mail {
+	name := beego.AppConfig.String("appname")
+	body := `Hi: ` + email + `! <br/><br/> 欢迎注册` + name + `，请将验证码填写到注册页面。<br/><br/>
+验证码：` + validateCode + `<br/><br/>
+如果这个请求不是由你发起的，那没问题，你不用担心，你可以安全地忽略这封邮件。<br/><br/>
+如果你有任何疑问，可以回复这封邮件向我们提问。<br/><br/>
+<front color="#888888">` + name + `</front>`
 
-	err := d.DialAndSend(mail)
-	return err
+	return &Mail{Email: email, Subject: "[" + name + "]" + " 用户注册", HtmlBody: body, Secret: true}
 }
 
-// SendRegistrationMail sends mail with registration information.
-func SendRegistrationMail(email, validateCode string) error {
-	port, _ := strconv.Atoi(mailConn["port"])
+// NewRemindMail returns the mail with remind information.
+func NewRemindMail(title, content, topicId, email, domain string) *Mail {
+	name := beego.AppConfig.String("appname")
+	body := content + `<p style="font-size:small;-webkit-text-size-adjust:none;color:#666;">-
+<br>
+You are receiving this because you are subscribed to this thread.
+<br> Reply to this email directly, 
+<a href="https://` + domain + "/t/" + topicId + `">view it on ` + name + `</a>` + `
+, or <a href="https://` + domain + `/settings/forum">unsubscribe` + `</a>`
 
-	mail := gomail.NewMessage()
+	return &Mail{Email: email, Subject: "Re: [" + name + "] " + title, HtmlBody: body}
+}
 
+// NewContactChangeMail returns the mail with the validate code to verify the new email address of the member.
+func NewContactChangeMail(email, memberId, validateCode string) *Mail {
 	name := beego.AppConfig.String("appname")
-	body := `Hi: ` + email + `! <br/><br/> 欢迎注册` + name + `，请将验证码填写到注册页面。<br/><br/>
+	body := `Hi: ` + memberId + `, <br/><br/> 你正在将 ` + name + ` 账号的邮箱修改为这个地址，请将验证码填写到设置页面。<br/><br/>
 验证码：` + validateCode + `<br/><br/>
 如果这个请求不是由你发起的，那没问题，你不用担心，你可以安全地忽略这封邮件。<br/><br/>
-如果你有任何疑问，可以回复这封邮件向我们提问。<br/><br/>
 <front color="#888888">` + name + `</front>`
 
-	mail.SetHeader("From", mail.FormatAddress(mailConn["user"], name))
-	mail.SetHeader("To", email)
-	mail.SetHeader("Subject", "["+name+"]"+" 用户注册") // set subject
-	mail.SetBody("text/html", body)                 // set body
+	return &Mail{Email: email, Subject: "[" + name + "]" + " 验证新邮箱", HtmlBody: body, Secret: true}
+}
 
-	d := gomail.NewDialer(mailConn["host"], port, mailConn["user"], mailConn["pass"])
+// NewContactChangedMail returns the mail notifying the old email address that the email or phone number of the member has been changed.
+func NewContactChangedMail(email, memberId, contactType, newValue string) *Mail {
+	name := beego.AppConfig.String("appname")
+	item := "邮箱"
+	if contactType == "phone" {
+		item = "手机号"
+	}
+	body := `Hi: ` + memberId + `, <br/><br/> 你在 ` + name + ` 的账号的` + item + `已修改为：` + newValue + `。<br/><br/>
+如果这个修改不是由你发起的，请立即重设密码并回复这封邮件联系我们。<br/><br/>
+<front color="#888888">` + name + `</front>`
 
-	err := d.DialAndSend(mail)
-	return err
+	return &Mail{Email: email, Subject: "[" + name + "]" + " 账号" + item + "已修改", HtmlBody: body}
 }
 
-// SendRemindMail sends mail with remind information.
-func SendRemindMail(title, content, topicId, email, domain string) error {
-	port, _ := strconv.Atoi(mailConn["port"])
-
-	mail := gomail.NewMessage()
+// NewNotificationMail returns the mail with a notification which isn't about a topic, e.g. a new follower.
+func NewNotificationMail(title, content, email, domain string) *Mail {
 	name := beego.AppConfig.String("appname")
 	body := content + `<p style="font-size:small;-webkit-text-size-adjust:none;color:#666;">-
 <br>
-You are receiving this because you are subscribed to this thread.
-<br> Reply to this email directly, 
-<a href="https://` + domain + "/t/" + topicId + `">view it on ` + name + `</a>` + `
+You are receiving this because of your notification settings.
+<br> <a href="https://` + domain + `/notifications">View it on ` + name + `</a>` + `
 , or <a href="https://` + domain + `/settings/forum">unsubscribe` + `</a>`
 
-	mail.SetHeader("From", mail.FormatAddress(mailConn["user"], name))
-	mail.SetHeader("To", email)
-	mail.SetHeader("Subject", "Re: ["+name+"] "+title) // set subject
-	mail.SetBody("text/html", body)                    // set body
+	return &Mail{Email: email, Subject: "[" + name + "] " + title, HtmlBody: body}
+}
+
+// NewDigestMail returns the digest of the member's forum activity, in both HTML and plain text.
+func NewDigestMail(subject, htmlBody, textBody, email string) *Mail {
+	name := beego.AppConfig.String("appname")
+
+	return &Mail{Email: email, Subject: "[" + name + "] " + subject, HtmlBody: htmlBody, TextBody: textBody}
+}
+
+// SendMail delivers the mail by SMTP.
+func SendMail(mail *Mail) error {
+	port, _ := strconv.Atoi(mailConn["port"])
+
+	message := gomail.NewMessage()
+	name := beego.AppConfig.String("appname")
+
+	message.SetHeader("From", message.FormatAddress(mailConn["user"], name))
+	message.SetHeader("To", mail.Email)
+	message.SetHeader("Subject", mail.Subject)
+	if mail.TextBody != "" {
+		message.SetBody("text/plain", mail.TextBody)
+		message.AddAlternative("text/html", mail.HtmlBody)
+	} else {
+		message.SetBody("text/html", mail.HtmlBody)
+	}
 
 	d := gomail.NewDialer(mailConn["host"], port, mailConn["user"], mailConn["pass"])
 
-	err := d.DialAndSend(mail)
-	return err
+	return d.DialAndSend(message)
 }
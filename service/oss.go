@@ -17,8 +17,7 @@ package service
 import (
 	"bytes"
 	"fmt"
-	"strconv"
-	"time"
+	"strings"
 
 	"github.com/astaxie/beego"
 	"github.com/qor/oss"
@@ -97,21 +96,6 @@ func Awss3Init() {
 	})
 }
 
-// UploadAvatarToOSS uploads an avatar to oss.
-func UploadAvatarToOSS(avatar []byte, memberId string) string {
-	if storage == nil {
-		fmt.Println("OSS config error")
-		return "oss conf error"
-	}
-
-	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
-	_, err := storage.Put(basicPath + "/" + memberId + "/avatar/" + timestamp + ".png", bytes.NewReader(avatar))
-	if err != nil {
-		panic(err)
-	}
-	return ossURL + "/" + memberId + "/avatar/" + timestamp + ".png"
-}
-
 // UploadFileToOSS uploads a file to the path, returns public URL
 func UploadFileToOSS(file []byte, path string) string {
 	if storage == nil {
@@ -121,7 +105,6 @@ func UploadFileToOSS(file []byte, path string) string {
 	_, err := storage.Put(basicPath + path, bytes.NewReader(file))
 	if err != nil {
 		panic(err)
-		return "OSS error"
 	}
 	return ossURL + path
 }
@@ -135,7 +118,25 @@ func DeleteOSSFile(filePath string) bool {
 	err := storage.Delete(filePath)
 	if err != nil {
 		panic(err)
-		return false
 	}
 	return true
 }
+
+// DeleteOSSDir deletes all the files under the directory, returns the number of deleted files.
+func DeleteOSSDir(dir string) int {
+	if storage == nil {
+		fmt.Println("OSS config error")
+		return 0
+	}
+	objects, err := storage.List(strings.TrimPrefix(basicPath+dir, "/"))
+	if err != nil {
+		panic(err)
+	}
+	for _, object := range objects {
+		err = storage.Delete(object.Path)
+		if err != nil {
+			panic(err)
+		}
+	}
+	return len(objects)
+}
@@ -0,0 +1,224 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/astaxie/beego"
+	"github.com/casbin/casnode/util"
+)
+
+// ProviderConfigPath is the file the identity providers are loaded from.
+// If it doesn't exist, the legacy provider keys in app.conf are used.
+var ProviderConfigPath = "conf/providers.json"
+
+// ProviderConfig describes an identity provider, type could be google, github, qq, wechat or oidc.
+// AuthUrl, TokenUrl and UserInfoUrl are optional and override the defaults of the type,
+// for oidc they are discovered from the issuer if empty.
+type ProviderConfig struct {
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`
+	DisplayName  string   `json:"displayName"`
+	ClientId     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret"`
+	State        string   `json:"state"`
+	Scopes       []string `json:"scopes"`
+	Issuer       string   `json:"issuer"`
+	AuthUrl      string   `json:"authUrl"`
+	TokenUrl     string   `json:"tokenUrl"`
+	UserInfoUrl  string   `json:"userInfoUrl"`
+}
+
+// ProviderInfo is the public part of ProviderConfig returned to the frontend.
+type ProviderInfo struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	DisplayName string   `json:"displayName"`
+	ClientId    string   `json:"clientId"`
+	AuthUrl     string   `json:"authUrl"`
+	Scopes      []string `json:"scopes"`
+}
+
+// UserInfo is the identity returned by a provider.
+// Subject is the stable id of the user at the provider. LegacySubject is what the subject used to be
+// for the provider, e.g. the github login, it's only used to migrate the identities linked before.
+type UserInfo struct {
+	Subject       string `json:"subject"`
+	LegacySubject string `json:"-"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"emailVerified"`
+	Avatar        string `json:"avatar"`
+}
+
+type IdProvider interface {
+	GetConfig() *ProviderConfig
+	GetAuthUrl() string
+	GetUserInfo(code, redirectUrl string) (*UserInfo, error)
+}
+
+var (
+	idProviders     = map[string]IdProvider{}
+	idProviderNames []string
+)
+
+// InitIdProviders loads the identity providers, client is used for all the requests to the providers.
+func InitIdProviders(client *http.Client) {
+	configs := []*ProviderConfig{}
+	if util.FileExist(ProviderConfigPath) {
+		data, err := ioutil.ReadFile(ProviderConfigPath)
+		if err != nil {
+			panic(err)
+		}
+		err = json.Unmarshal(data, &configs)
+		if err != nil {
+			panic(err)
+		}
+	} else {
+		configs = getLegacyProviderConfigs()
+	}
+
+	idProviders = map[string]IdProvider{}
+	idProviderNames = nil
+	for _, config := range configs {
+		if config.ClientId == "" {
+			continue
+		}
+		if config.Name == "" {
+			config.Name = config.Type
+		}
+		if _, ok := idProviders[config.Name]; ok {
+			panic(fmt.Errorf("duplicated identity provider: %s", config.Name))
+		}
+
+		idProvider := newIdProvider(config, client)
+		if idProvider == nil {
+			panic(fmt.Errorf("unknown identity provider type: %s", config.Type))
+		}
+		idProviders[config.Name] = idProvider
+		idProviderNames = append(idProviderNames, config.Name)
+	}
+}
+
+func newIdProvider(config *ProviderConfig, client *http.Client) IdProvider {
+	switch config.Type {
+	case "google":
+		return newGoogleProvider(config, client)
+	case "github":
+		return newGithubProvider(config, client)
+	case "oidc":
+		return newOidcProvider(config, client)
+	case "qq":
+		return newQQProvider(config, client)
+	case "wechat":
+		return newWeChatProvider(config, client)
+	}
+	return nil
+}
+
+// getLegacyProviderConfigs reads the provider keys from app.conf.
+func getLegacyProviderConfigs() []*ProviderConfig {
+	return []*ProviderConfig{
+		{
+			Name:         "google",
+			Type:         "google",
+			DisplayName:  "Google",
+			ClientId:     beego.AppConfig.String("GoogleAuthClientID"),
+			ClientSecret: beego.AppConfig.String("GoogleAuthClientSecret"),
+			State:        beego.AppConfig.String("GoogleAuthState"),
+		},
+		{
+			Name:         "github",
+			Type:         "github",
+			DisplayName:  "GitHub",
+			ClientId:     beego.AppConfig.String("GithubAuthClientID"),
+			ClientSecret: beego.AppConfig.String("GithubAuthClientSecret"),
+			State:        beego.AppConfig.String("GithubAuthState"),
+		},
+		{
+			Name:         "qq",
+			Type:         "qq",
+			DisplayName:  "QQ",
+			ClientId:     beego.AppConfig.String("QQAPPID"),
+			ClientSecret: beego.AppConfig.String("QQAPPKey"),
+			State:        beego.AppConfig.String("QQAuthState"),
+		},
+		{
+			Name:         "wechat",
+			Type:         "wechat",
+			DisplayName:  "WeChat",
+			ClientId:     beego.AppConfig.String("WeChatAPPID"),
+			ClientSecret: beego.AppConfig.String("WeChatKey"),
+			State:        beego.AppConfig.String("WeChatAuthState"),
+		},
+	}
+}
+
+// GetIdProvider returns the identity provider with the name, nil if it's not configured.
+func GetIdProvider(name string) IdProvider {
+	return idProviders[name]
+}
+
+// GetIdProviderInfos returns the configured identity providers without their secrets.
+func GetIdProviderInfos() []*ProviderInfo {
+	res := []*ProviderInfo{}
+	for _, name := range idProviderNames {
+		idProvider := idProviders[name]
+		config := idProvider.GetConfig()
+		res = append(res, &ProviderInfo{
+			Name:        config.Name,
+			Type:        config.Type,
+			DisplayName: config.DisplayName,
+			ClientId:    config.ClientId,
+			AuthUrl:     idProvider.GetAuthUrl(),
+			Scopes:      config.Scopes,
+		})
+	}
+	return res
+}
+
+// getJson sends the request and decodes the json response into v.
+func getJson(client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d: %s", req.URL.Host, resp.StatusCode, string(data))
+	}
+	return json.Unmarshal(data, v)
+}
+
+// getJsonWithToken gets the url with the bearer token and decodes the json response into v.
+func getJsonWithToken(client *http.Client, url, token string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return getJson(client, req, v)
+}
@@ -0,0 +1,275 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// oauthProvider is the standard authorization code flow, the providers only differ in how the user info is fetched.
+type oauthProvider struct {
+	config      *ProviderConfig
+	client      *http.Client
+	getUserInfo func(token *oauth2.Token) (*UserInfo, error)
+}
+
+func (p *oauthProvider) GetConfig() *ProviderConfig {
+	return p.config
+}
+
+func (p *oauthProvider) GetAuthUrl() string {
+	return p.config.AuthUrl
+}
+
+func (p *oauthProvider) getOAuthConfig(redirectUrl string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.config.ClientId,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  redirectUrl,
+		Scopes:       p.config.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  p.config.AuthUrl,
+			TokenURL: p.config.TokenUrl,
+		},
+	}
+}
+
+func (p *oauthProvider) GetUserInfo(code, redirectUrl string) (*UserInfo, error) {
+	// https://github.com/golang/oauth2/issues/123#issuecomment-103715338
+	ctx := context.WithValue(oauth2.NoContext, oauth2.HTTPClient, p.client)
+	token, err := p.getOAuthConfig(redirectUrl).Exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid() {
+		return nil, fmt.Errorf("invalid token from %s", p.config.Name)
+	}
+
+	return p.getUserInfo(token)
+}
+
+func setDefaultEndpoints(config *ProviderConfig, authUrl, tokenUrl, userInfoUrl string, scopes []string) {
+	if config.AuthUrl == "" {
+		config.AuthUrl = authUrl
+	}
+	if config.TokenUrl == "" {
+		config.TokenUrl = tokenUrl
+	}
+	if config.UserInfoUrl == "" {
+		config.UserInfoUrl = userInfoUrl
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = scopes
+	}
+}
+
+// newGoogleProvider uses the sub as the subject, the email is the legacy subject stored by the member table.
+func newGoogleProvider(config *ProviderConfig, client *http.Client) IdProvider {
+	setDefaultEndpoints(config,
+		"https://accounts.google.com/o/oauth2/auth",
+		"https://accounts.google.com/o/oauth2/token",
+		"https://openidconnect.googleapis.com/v1/userinfo",
+		[]string{"openid", "profile", "email"})
+
+	p := &oauthProvider{config: config, client: client}
+	p.getUserInfo = func(token *oauth2.Token) (*UserInfo, error) {
+		// the v2 userinfo endpoint names the fields id and verified_email
+		var userInfo struct {
+			Sub           string `json:"sub"`
+			Id            string `json:"id"`
+			Email         string `json:"email"`
+			EmailVerified bool   `json:"email_verified"`
+			VerifiedEmail bool   `json:"verified_email"`
+			Picture       string `json:"picture"`
+		}
+		err := getJsonWithToken(client, config.UserInfoUrl, token.AccessToken, &userInfo)
+		if err != nil {
+			return nil, err
+		}
+		if userInfo.Sub == "" {
+			userInfo.Sub = userInfo.Id
+		}
+		if userInfo.Sub == "" {
+			return nil, fmt.Errorf("no subject returned from %s", config.Name)
+		}
+
+		return &UserInfo{
+			Subject:       userInfo.Sub,
+			LegacySubject: userInfo.Email,
+			Username:      userInfo.Email,
+			Email:         userInfo.Email,
+			EmailVerified: userInfo.EmailVerified || userInfo.VerifiedEmail,
+			Avatar:        userInfo.Picture,
+		}, nil
+	}
+	return p
+}
+
+// newGithubProvider uses the numeric id as the subject, the login is the legacy subject stored by the member table.
+func newGithubProvider(config *ProviderConfig, client *http.Client) IdProvider {
+	setDefaultEndpoints(config,
+		"https://github.com/login/oauth/authorize",
+		"https://github.com/login/oauth/access_token",
+		"https://api.github.com/user",
+		[]string{"user:email", "read:user"})
+
+	p := &oauthProvider{config: config, client: client}
+	p.getUserInfo = func(token *oauth2.Token) (*UserInfo, error) {
+		var userInfo struct {
+			Id        int64  `json:"id"`
+			Login     string `json:"login"`
+			AvatarUrl string `json:"avatar_url"`
+		}
+		var emails []struct {
+			Email    string `json:"email"`
+			Primary  bool   `json:"primary"`
+			Verified bool   `json:"verified"`
+		}
+
+		var wg sync.WaitGroup
+		var userErr, emailErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			userErr = getJsonWithToken(client, config.UserInfoUrl, token.AccessToken, &userInfo)
+		}()
+		go func() {
+			defer wg.Done()
+			emailErr = getJsonWithToken(client, strings.TrimSuffix(config.UserInfoUrl, "/")+"/emails", token.AccessToken, &emails)
+		}()
+		wg.Wait()
+		if userErr != nil {
+			return nil, userErr
+		}
+		if emailErr != nil {
+			return nil, emailErr
+		}
+		if userInfo.Id == 0 {
+			return nil, fmt.Errorf("no id returned from %s", config.Name)
+		}
+
+		res := &UserInfo{
+			Subject:       strconv.FormatInt(userInfo.Id, 10),
+			LegacySubject: userInfo.Login,
+			Username:      userInfo.Login,
+			Avatar:        userInfo.AvatarUrl,
+		}
+		for _, v := range emails {
+			if v.Primary {
+				res.Email = v.Email
+				res.EmailVerified = v.Verified
+				break
+			}
+		}
+		return res, nil
+	}
+	return p
+}
+
+// oidcProvider is a generic OpenID Connect provider, its endpoints are discovered from the issuer.
+type oidcProvider struct {
+	oauthProvider
+	lock       sync.Mutex
+	discovered bool
+}
+
+func newOidcProvider(config *ProviderConfig, client *http.Client) IdProvider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "profile", "email"}
+	}
+
+	p := &oidcProvider{oauthProvider: oauthProvider{config: config, client: client}}
+	p.getUserInfo = func(token *oauth2.Token) (*UserInfo, error) {
+		var claims struct {
+			Subject           string `json:"sub"`
+			PreferredUsername string `json:"preferred_username"`
+			Name              string `json:"name"`
+			Email             string `json:"email"`
+			EmailVerified     bool   `json:"email_verified"`
+			Picture           string `json:"picture"`
+		}
+		err := getJsonWithToken(client, config.UserInfoUrl, token.AccessToken, &claims)
+		if err != nil {
+			return nil, err
+		}
+		if claims.Subject == "" {
+			return nil, fmt.Errorf("no subject returned from %s", config.Name)
+		}
+
+		res := &UserInfo{
+			Subject:       claims.Subject,
+			Username:      claims.PreferredUsername,
+			Email:         claims.Email,
+			EmailVerified: claims.EmailVerified,
+			Avatar:        claims.Picture,
+		}
+		if res.Username == "" {
+			res.Username = claims.Name
+		}
+		return res, nil
+	}
+	return p
+}
+
+// discover fills the missing endpoints from the issuer's discovery document, only once it succeeds.
+func (p *oidcProvider) discover() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	config := p.config
+	if p.discovered || (config.AuthUrl != "" && config.TokenUrl != "" && config.UserInfoUrl != "") {
+		return nil
+	}
+	if config.Issuer == "" {
+		return fmt.Errorf("no issuer configured for %s", config.Name)
+	}
+
+	var doc struct {
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	}
+	err := getJsonWithToken(p.client, strings.TrimSuffix(config.Issuer, "/")+"/.well-known/openid-configuration", "", &doc)
+	if err != nil {
+		return err
+	}
+
+	setDefaultEndpoints(config, doc.AuthorizationEndpoint, doc.TokenEndpoint, doc.UserinfoEndpoint, nil)
+	p.discovered = true
+	return nil
+}
+
+func (p *oidcProvider) GetAuthUrl() string {
+	err := p.discover()
+	if err != nil {
+		return ""
+	}
+	return p.config.AuthUrl
+}
+
+func (p *oidcProvider) GetUserInfo(code, redirectUrl string) (*UserInfo, error) {
+	err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+	return p.oauthProvider.GetUserInfo(code, redirectUrl)
+}
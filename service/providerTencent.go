@@ -0,0 +1,182 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// qqProvider doesn't follow the standard token response, so the flow is done by hand.
+// The open id is used as the subject.
+type qqProvider struct {
+	config *ProviderConfig
+	client *http.Client
+}
+
+func newQQProvider(config *ProviderConfig, client *http.Client) IdProvider {
+	setDefaultEndpoints(config,
+		"https://graph.qq.com/oauth2.0/authorize",
+		"https://graph.qq.com/oauth2.0/token",
+		"https://graph.qq.com/user/get_user_info",
+		[]string{"get_user_info"})
+	return &qqProvider{config: config, client: client}
+}
+
+func (p *qqProvider) GetConfig() *ProviderConfig {
+	return p.config
+}
+
+func (p *qqProvider) GetAuthUrl() string {
+	return p.config.AuthUrl
+}
+
+func (p *qqProvider) getText(url string) (string, error) {
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (p *qqProvider) GetUserInfo(code, redirectUrl string) (*UserInfo, error) {
+	params := url.Values{}
+	params.Add("grant_type", "authorization_code")
+	params.Add("client_id", p.config.ClientId)
+	params.Add("client_secret", p.config.ClientSecret)
+	params.Add("code", code)
+	params.Add("redirect_uri", redirectUrl)
+
+	tokenContent, err := p.getText(fmt.Sprintf("%s?%s", p.config.TokenUrl, params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	tokenRes := regexp.MustCompile("token=(.*?)&").FindStringSubmatch(tokenContent)
+	if tokenRes == nil {
+		return nil, fmt.Errorf("no token returned from %s: %s", p.config.Name, tokenContent)
+	}
+	token := tokenRes[1]
+
+	openIdContent, err := p.getText(fmt.Sprintf("https://graph.qq.com/oauth2.0/me?access_token=%s", token))
+	if err != nil {
+		return nil, err
+	}
+	openIdRes := regexp.MustCompile("\"openid\":\"(.*?)\"}").FindStringSubmatch(openIdContent)
+	if openIdRes == nil || openIdRes[1] == "" {
+		return nil, fmt.Errorf("no open id returned from %s: %s", p.config.Name, openIdContent)
+	}
+	openId := openIdRes[1]
+
+	var userInfo struct {
+		Ret       int    `json:"ret"`
+		Nickname  string `json:"nickname"`
+		AvatarUrl string `json:"figureurl_qq_1"`
+	}
+	userInfoUrl := fmt.Sprintf("%s?access_token=%s&oauth_consumer_key=%s&openid=%s", p.config.UserInfoUrl, token, p.config.ClientId, openId)
+	req, err := http.NewRequest("GET", userInfoUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = getJson(p.client, req, &userInfo)
+	if err != nil {
+		return nil, err
+	}
+	if userInfo.Ret != 0 {
+		return nil, fmt.Errorf("failed to get user info from %s, ret: %d", p.config.Name, userInfo.Ret)
+	}
+
+	return &UserInfo{
+		Subject:  openId,
+		Username: userInfo.Nickname,
+		Avatar:   userInfo.AvatarUrl,
+	}, nil
+}
+
+// weChatProvider uses appid and secret instead of the standard client credentials.
+// The open id is used as the subject.
+type weChatProvider struct {
+	config *ProviderConfig
+	client *http.Client
+}
+
+func newWeChatProvider(config *ProviderConfig, client *http.Client) IdProvider {
+	setDefaultEndpoints(config,
+		"https://open.weixin.qq.com/connect/qrconnect",
+		"https://api.weixin.qq.com/sns/oauth2/access_token",
+		"https://api.weixin.qq.com/sns/userinfo",
+		[]string{"snsapi_login"})
+	return &weChatProvider{config: config, client: client}
+}
+
+func (p *weChatProvider) GetConfig() *ProviderConfig {
+	return p.config
+}
+
+func (p *weChatProvider) GetAuthUrl() string {
+	return p.config.AuthUrl
+}
+
+func (p *weChatProvider) GetUserInfo(code, redirectUrl string) (*UserInfo, error) {
+	params := url.Values{}
+	params.Add("code", code)
+	params.Add("grant_type", "authorization_code")
+	params.Add("appid", p.config.ClientId)
+	params.Add("secret", p.config.ClientSecret)
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		Openid      string `json:"openid"`
+		ErrMsg      string `json:"errmsg"`
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s?%s", p.config.TokenUrl, params.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	err = getJson(p.client, req, &tokenResp)
+	if err != nil {
+		return nil, err
+	}
+	if tokenResp.Openid == "" {
+		return nil, fmt.Errorf("no open id returned from %s: %s", p.config.Name, tokenResp.ErrMsg)
+	}
+
+	var userInfo struct {
+		Nickname  string `json:"nickname"`
+		AvatarUrl string `json:"headimgurl"`
+	}
+	req, err = http.NewRequest("GET", fmt.Sprintf("%s?access_token=%s&openid=%s", p.config.UserInfoUrl, tokenResp.AccessToken, tokenResp.Openid), nil)
+	if err != nil {
+		return nil, err
+	}
+	err = getJson(p.client, req, &userInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserInfo{
+		Subject:  tokenResp.Openid,
+		Username: userInfo.Nickname,
+		Avatar:   userInfo.AvatarUrl,
+	}, nil
+}
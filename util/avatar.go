@@ -0,0 +1,165 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"sort"
+)
+
+// MaxAvatarPixels limits the decoded size of avatars, so that small files can't expand into huge images.
+const MaxAvatarPixels = 4096 * 4096
+
+var ErrInvalidAvatar = errors.New("the avatar should be a PNG or JPEG image")
+
+// ProcessAvatar decodes the PNG or JPEG image, crops it to a square in the center and resizes it
+// to each of the sizes. Returns the PNG encoded images by size.
+func ProcessAvatar(data []byte, sizes []int) (map[int][]byte, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "png" && format != "jpeg") {
+		return nil, ErrInvalidAvatar
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxAvatarPixels {
+		return nil, errors.New("the avatar is too large")
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidAvatar
+	}
+
+	bounds := src.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+	x0 := bounds.Min.X + (bounds.Dx()-side)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-side)/2
+	var img image.Image = toRGBA(src, image.Rect(x0, y0, x0+side, y0+side))
+
+	// the smaller sizes are resized from the larger ones
+	sorted := append([]int{}, sizes...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+
+	res := map[int][]byte{}
+	for _, size := range sorted {
+		img = resizeSquare(img.(*image.RGBA), size)
+		buf := bytes.Buffer{}
+		err = png.Encode(&buf, img)
+		if err != nil {
+			return nil, err
+		}
+		res[size] = buf.Bytes()
+	}
+
+	return res, nil
+}
+
+func toRGBA(src image.Image, rect image.Rectangle) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	for y := 0; y < rect.Dy(); y++ {
+		for x := 0; x < rect.Dx(); x++ {
+			dst.Set(x, y, src.At(rect.Min.X+x, rect.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// resizeSquare resizes the square image by averaging the source pixels covered by each target pixel.
+func resizeSquare(src *image.RGBA, size int) *image.RGBA {
+	side := src.Bounds().Dx()
+	if side == size {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		sy0, sy1 := y*side/size, (y+1)*side/size
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := 0; x < size; x++ {
+			sx0, sx1 := x*side/size, (x+1)*side/size
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+
+			var r, g, b, a, n int
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					i := src.PixOffset(sx, sy)
+					r += int(src.Pix[i])
+					g += int(src.Pix[i+1])
+					b += int(src.Pix[i+2])
+					a += int(src.Pix[i+3])
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// GenerateIdenticon returns a PNG identicon of the size, the same seed always gets the same image.
+// It's a horizontally symmetric 5x5 pattern colored by the md5 of the seed.
+func GenerateIdenticon(seed string, size int) []byte {
+	hash := md5.Sum([]byte(seed))
+	fg := color.RGBA{R: hash[13]/2 + 64, G: hash[14]/2 + 64, B: hash[15]/2 + 64, A: 255}
+	bg := color.RGBA{R: 240, G: 240, B: 240, A: 255}
+
+	const grid = 5
+	cell := size / (grid + 1)
+	margin := (size - cell*grid) / 2
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.SetRGBA(x, y, bg)
+		}
+	}
+
+	for row := 0; row < grid; row++ {
+		for col := 0; col < (grid+1)/2; col++ {
+			if hash[row*3+col]%2 != 0 {
+				continue
+			}
+			for _, c := range []int{col, grid - 1 - col} {
+				for y := margin + row*cell; y < margin+(row+1)*cell; y++ {
+					for x := margin + c*cell; x < margin+(c+1)*cell; x++ {
+						img.SetRGBA(x, y, fg)
+					}
+				}
+			}
+		}
+	}
+
+	buf := bytes.Buffer{}
+	err := png.Encode(&buf, img)
+	if err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
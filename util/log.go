@@ -17,10 +17,12 @@ package util
 import (
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"strings"
 
+	"github.com/astaxie/beego"
 	"github.com/astaxie/beego/context"
 	"github.com/astaxie/beego/logs"
 )
@@ -47,6 +49,49 @@ func GetIPInfo(clientIP string) string {
 }
 
 func getIPFromRequest(req *http.Request) string {
+	return GetIPInfo(getIPChainFromRequest(req))
+}
+
+// GetClientIp returns the ip of the client. X-Forwarded-For is only trusted when the request comes from
+// one of the trustedProxies in app.conf, the client is the last entry not appended by a trusted proxy.
+func GetClientIp(req *http.Request) string {
+	ip := getRemoteIp(req)
+	if !isTrustedProxy(ip) {
+		return ip
+	}
+
+	ips := strings.Split(req.Header.Get("x-forwarded-for"), ",")
+	for i := len(ips) - 1; i >= 0; i-- {
+		forwarded := strings.TrimSpace(ips[i])
+		if forwarded == "" {
+			break
+		}
+		ip = forwarded
+		if !isTrustedProxy(ip) {
+			break
+		}
+	}
+	return ip
+}
+
+func isTrustedProxy(ip string) bool {
+	for _, proxy := range strings.Split(beego.AppConfig.String("trustedProxies"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" && proxy == ip {
+			return true
+		}
+	}
+	return false
+}
+
+func getRemoteIp(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+func getIPChainFromRequest(req *http.Request) string {
 	clientIP := req.Header.Get("x-forwarded-for")
 	if clientIP == "" {
 		ipPort := strings.Split(req.RemoteAddr, ":")
@@ -60,7 +105,7 @@ func getIPFromRequest(req *http.Request) string {
 		}
 	}
 
-	return GetIPInfo(clientIP)
+	return clientIP
 }
 
 func LogInfo(ctx *context.Context, f string, v ...interface{}) {
@@ -67,3 +67,13 @@ func GetTimeYear(year int) string {
 func GetDateStr() string {
 	return time.Now().Format("20060102")
 }
+
+// GetTimeAfterMinute returns the time after the specified duration(minute) from the formatted time.
+func GetTimeAfterMinute(t string, minute int) string {
+	tm, err := time.Parse(time.RFC3339, t)
+	if err != nil {
+		panic(err)
+	}
+	res := tm.Add(time.Duration(minute) * time.Minute)
+	return res.Format(time.RFC3339)
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters (RFC 6238), the defaults every authenticator app supports.
+const (
+	TotpPeriod = 30 // seconds
+	TotpDigits = 6
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTotpSecret returns a random base32 encoded secret.
+func GenerateTotpSecret() string {
+	return totpEncoding.EncodeToString(GetRandomBytes(20))
+}
+
+// GetTotpStep returns the time step of the time.
+func GetTotpStep(t time.Time) int64 {
+	return t.Unix() / TotpPeriod
+}
+
+// GetTotpCode returns the code of the secret at the time step.
+func GetTotpCode(secret string, step int64) string {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		panic(err)
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0xf
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+// GetTotpUri returns the provisioning uri to be shown as a QR code to authenticator apps.
+func GetTotpUri(issuer, account, secret string) string {
+	params := url.Values{}
+	params.Add("secret", secret)
+	params.Add("issuer", issuer)
+	params.Add("algorithm", "SHA1")
+	params.Add("digits", fmt.Sprintf("%d", TotpDigits))
+	params.Add("period", fmt.Sprintf("%d", TotpPeriod))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// GetRandomBytes returns n bytes from crypto/rand.
+func GetRandomBytes(n int) []byte {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// GenerateRecoveryCode returns a random code like "a1b2c-3d4e5".
+func GenerateRecoveryCode() string {
+	code := hex.EncodeToString(GetRandomBytes(5))
+	return code[:5] + "-" + code[5:]
+}
+
+// GetSha256Hash returns the hex encoded sha256 of the string.
+func GetSha256Hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
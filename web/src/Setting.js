@@ -101,6 +101,20 @@ export function deleteRow(array, i) {
   return [...array.slice(0, i), ...array.slice(i + 1)];
 }
 
+export function getLinkedAccount(member, provider) {
+  const account = member?.linkedAccounts?.find(
+    (item) => item.provider === provider
+  );
+  return account === undefined ? "" : account.username;
+}
+
+export function getLinkedAccountTime(member, provider) {
+  const account = member?.linkedAccounts?.find(
+    (item) => item.provider === provider
+  );
+  return account === undefined ? "" : account.createdTime;
+}
+
 export function getFormattedDate(date) {
   date = date?.replace("T", " ");
   date = date?.replace("+08:00", " +08:00");
@@ -774,12 +774,12 @@ class AdminMember extends React.Component {
                       Google
                     </td>
                     <td width="200" align="left">
-                      {member?.googleAccount.length === 0 ? (
+                      {Setting.getLinkedAccount(member, "google").length === 0 ? (
                         <span className="gray">
                           {i18next.t("member:Not set")}
                         </span>
                       ) : (
-                        <code>{member?.googleAccount}</code>
+                        <code>{Setting.getLinkedAccount(member, "google")}</code>
                       )}
                     </td>
                   </tr>
@@ -788,12 +788,12 @@ class AdminMember extends React.Component {
                       Github
                     </td>
                     <td width="200" align="left">
-                      {member?.githubAccount.length === 0 ? (
+                      {Setting.getLinkedAccount(member, "github").length === 0 ? (
                         <span className="gray">
                           {i18next.t("member:Not set")}
                         </span>
                       ) : (
-                        <code>{member?.githubAccount}</code>
+                        <code>{Setting.getLinkedAccount(member, "github")}</code>
                       )}
                     </td>
                   </tr>
@@ -802,12 +802,12 @@ class AdminMember extends React.Component {
                       {i18next.t("setting:WeChat")}
                     </td>
                     <td width="200" align="left">
-                      {member?.weChatAccount.length === 0 ? (
+                      {Setting.getLinkedAccount(member, "wechat").length === 0 ? (
                         <span className="gray">
                           {i18next.t("member:Not set")}
                         </span>
                       ) : (
-                        <code>{member?.weChatAccount}</code>
+                        <code>{Setting.getLinkedAccount(member, "wechat")}</code>
                       )}
                     </td>
                   </tr>
@@ -816,16 +816,16 @@ class AdminMember extends React.Component {
                       QQ
                     </td>
                     <td width="200" align="left">
-                      {member?.qqAccount.length === 0 ? (
+                      {Setting.getLinkedAccount(member, "qq").length === 0 ? (
                         <span className="gray">
                           {i18next.t("member:Not set")}
                         </span>
                       ) : (
-                        <code>{member?.qqAccount}</code>
+                        <code>{Setting.getLinkedAccount(member, "qq")}</code>
                       )}
                     </td>
                     <td width="100" align="left">
-                      {member?.qqVerifiedTime.length === 0 ? (
+                      {Setting.getLinkedAccountTime(member, "qq").length === 0 ? (
                         <span className="negative">
                           {i18next.t("member:Unverified")}
                         </span>
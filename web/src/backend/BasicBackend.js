@@ -49,6 +49,13 @@ export function getCaptcha() {
   }).then((res) => res.json());
 }
 
+export function getSignupMode() {
+  return fetch(`${Setting.ServerUrl}/api/get-signup-mode`, {
+    method: "GET",
+    credentials: "include",
+  }).then((res) => res.json());
+}
+
 export function getValidateCode(information, verifyType) {
   return fetch(
     `${Setting.ServerUrl}/api/get-validate-code?information=${information}&type=${verifyType}`,
@@ -51,6 +51,14 @@ class MemberBox extends React.Component {
 
   getMember() {
     MemberBackend.getMember(this.state.memberId).then((res) => {
+      // the member has been renamed, the old name leads to the new profile
+      if (
+        res !== null &&
+        res.id !== this.state.memberId &&
+        decodeURIComponent(window.location.pathname) !== `/member/${res.id}`
+      ) {
+        this.props.history.replace(`/member/${res.id}`);
+      }
       this.setState({
         member: res,
       });
@@ -317,9 +325,9 @@ class MemberBox extends React.Component {
               &nbsp;{this.state.member?.location}
             </a>
           ) : null}
-          {this.state.member?.githubAccount.length !== 0 ? (
+          {Setting.getLinkedAccount(this.state.member, "github").length !== 0 ? (
             <a
-              href={`https://github.com/${this.state.member?.githubAccount}`}
+              href={`https://github.com/${Setting.getLinkedAccount(this.state.member, "github")}`}
               className="social_label"
               target="_blank"
               rel="nofollow noopener noreferrer"
@@ -330,12 +338,12 @@ class MemberBox extends React.Component {
                 alt="GitHub"
                 align="absmiddle"
               />{" "}
-              &nbsp;{this.state.member?.githubAccount}
+              &nbsp;{Setting.getLinkedAccount(this.state.member, "github")}
             </a>
           ) : null}
-          {this.state.member?.googleAccount.length !== 0 ? (
+          {Setting.getLinkedAccount(this.state.member, "google").length !== 0 ? (
             <a
-              href={`mailto:${this.state.member?.googleAccount}`}
+              href={`mailto:${Setting.getLinkedAccount(this.state.member, "google")}`}
               className="social_label"
               target="_blank"
               rel="nofollow noopener noreferrer"
@@ -346,7 +354,7 @@ class MemberBox extends React.Component {
                 alt="Google"
                 align="absmiddle"
               />{" "}
-              &nbsp;{this.state.member?.googleAccount}
+              &nbsp;{Setting.getLinkedAccount(this.state.member, "google")}
             </a>
           ) : null}
         </div>
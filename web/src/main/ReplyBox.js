@@ -39,6 +39,7 @@ class ReplyBox extends React.Component {
       topicId: props.match.params.topicId,
       topic: null,
       replies: [],
+      expandedReplies: {},
       reply: "",
       memberList: [],
       replyThanksCost: 10,
@@ -300,6 +301,33 @@ class ReplyBox extends React.Component {
         </div>
         {Setting.PcBrowser ? this.showPageColumn() : null}
         {this.state.replies?.map((reply, i) => {
+          if (reply.blocked && !this.state.expandedReplies[reply.id]) {
+            return (
+              <div
+                id={`r_${reply.id}`}
+                className={`cell ${this.props.topic.nodeId}`}
+              >
+                <span className="gray">
+                  {i18next.t("reply:This reply is from a member you blocked")}
+                  &nbsp;
+                  <a
+                    href="#;"
+                    onClick={(event) => {
+                      event.preventDefault();
+                      this.setState({
+                        expandedReplies: {
+                          ...this.state.expandedReplies,
+                          [reply.id]: true,
+                        },
+                      });
+                    }}
+                  >
+                    {i18next.t("reply:Show")}
+                  </a>
+                </span>
+              </div>
+            );
+          }
           return (
             <div
               id={`r_${reply.id}`}
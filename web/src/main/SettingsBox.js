@@ -604,7 +604,7 @@ class SettingsBox extends React.Component {
                   <td width="120" align="right">
                     Google
                   </td>
-                  {account?.googleAccount === "" ? (
+                  {Setting.getLinkedAccount(account, "google") === "" ? (
                     <td width="auto" align="left">
                       <a
                         onClick={() => Setting.getGoogleAuthCode("link")}
@@ -615,12 +615,12 @@ class SettingsBox extends React.Component {
                     </td>
                   ) : (
                     <td width="auto" align="left">
-                      <code>{account?.googleAccount}</code>
+                      <code>{Setting.getLinkedAccount(account, "google")}</code>
                     </td>
                   )}
                 </tr>
               ) : null}
-              {account?.googleAccount === "" ? null : (
+              {Setting.getLinkedAccount(account, "google") === "" ? null : (
                 <tr>
                   <td width="120" align="right" />
                   <td width="auto" align="left">
@@ -635,7 +635,7 @@ class SettingsBox extends React.Component {
                   <td width="120" align="right">
                     Github
                   </td>
-                  {account?.githubAccount === "" ? (
+                  {Setting.getLinkedAccount(account, "github") === "" ? (
                     <td width="auto" align="left">
                       <a
                         onClick={() => Setting.getGithubAuthCode("link")}
@@ -646,12 +646,12 @@ class SettingsBox extends React.Component {
                     </td>
                   ) : (
                     <td width="auto" align="left">
-                      <code>{account?.githubAccount}</code>
+                      <code>{Setting.getLinkedAccount(account, "github")}</code>
                     </td>
                   )}
                 </tr>
               ) : null}
-              {account?.githubAccount === "" ? null : (
+              {Setting.getLinkedAccount(account, "github") === "" ? null : (
                 <tr>
                   <td width="120" align="right" />
                   <td width="auto" align="left">
@@ -666,7 +666,7 @@ class SettingsBox extends React.Component {
                   <td width="120" align="right">
                     {i18next.t("setting:WeChat")}
                   </td>
-                  {account?.weChatAccount === "" ? (
+                  {Setting.getLinkedAccount(account, "wechat") === "" ? (
                     <td width="auto" align="left">
                       <a
                         onClick={() => Setting.getWeChatAuthCode("link")}
@@ -677,12 +677,12 @@ class SettingsBox extends React.Component {
                     </td>
                   ) : (
                     <td width="auto" align="left">
-                      <code>{account?.weChatAccount}</code>
+                      <code>{Setting.getLinkedAccount(account, "wechat")}</code>
                     </td>
                   )}
                 </tr>
               ) : null}
-              {account?.qqVerifiedTime.length !== 0 ? (
+              {Setting.getLinkedAccountTime(account, "wechat").length !== 0 ? (
                 <tr>
                   <td width="120" align="right">
                     {i18next.t("setting:Modify WeChat")}
@@ -690,7 +690,7 @@ class SettingsBox extends React.Component {
                   <td width="auto" align="left">
                     <span className="green">
                       {i18next.t("setting:Verified on")}{" "}
-                      {Setting.getFormattedDate(account?.wechatVerifiedTime)}
+                      {Setting.getFormattedDate(Setting.getLinkedAccountTime(account, "wechat"))}
                     </span>
                   </td>
                 </tr>
@@ -700,7 +700,7 @@ class SettingsBox extends React.Component {
                   <td width="120" align="right">
                     QQ
                   </td>
-                  {account?.qqAccount === "" ? (
+                  {Setting.getLinkedAccount(account, "qq") === "" ? (
                     <td width="auto" align="left">
                       <a
                         onClick={() => Setting.getQQAuthCode("link")}
@@ -711,12 +711,12 @@ class SettingsBox extends React.Component {
                     </td>
                   ) : (
                     <td width="auto" align="left">
-                      <code>{account?.qqAccount}</code>
+                      <code>{Setting.getLinkedAccount(account, "qq")}</code>
                     </td>
                   )}
                 </tr>
               ) : null}
-              {account?.qqVerifiedTime.length !== 0 ? (
+              {Setting.getLinkedAccountTime(account, "qq").length !== 0 ? (
                 <tr>
                   <td width="120" align="right">
                     {i18next.t("setting:QQ Verification")}
@@ -724,7 +724,7 @@ class SettingsBox extends React.Component {
                   <td width="auto" align="left">
                     <span className="green">
                       {i18next.t("setting:Verified on")}{" "}
-                      {Setting.getFormattedDate(account?.qqVerifiedTime)}
+                      {Setting.getFormattedDate(Setting.getLinkedAccountTime(account, "qq"))}
                     </span>
                   </td>
                 </tr>
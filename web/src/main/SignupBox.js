@@ -37,6 +37,7 @@ class SignupBox extends React.Component {
       sendStatus: false,
       showValidateCode: false,
       message: "",
+      signupMode: "open",
     };
   }
 
@@ -50,6 +51,17 @@ class SignupBox extends React.Component {
 
   componentDidMount() {
     this.initPostForm();
+    this.getSignupMode();
+  }
+
+  getSignupMode() {
+    BasicBackend.getSignupMode().then((res) => {
+      if (res.status === "ok") {
+        this.setState({
+          signupMode: res.data,
+        });
+      }
+    });
   }
 
   componentWillReceiveProps(newProps) {
@@ -412,6 +424,24 @@ class SignupBox extends React.Component {
                 {this.state.signupMethod === "email"
                   ? this.renderValidateCode()
                   : null}
+                {this.state.signupMode === "invite" ? (
+                  <tr>
+                    <td width="120" align="right">
+                      {i18next.t("signup:Invite code")}
+                    </td>
+                    <td width="auto" align="left">
+                      <input
+                        type="text"
+                        className="sl"
+                        name="inviteCode"
+                        onChange={(event) =>
+                          this.updateFormField("inviteCode", event.target.value)
+                        }
+                        autoComplete="off"
+                      />
+                    </td>
+                  </tr>
+                ) : null}
                 <tr>
                   <td width="120" align="right">
                     {i18next.t("signup:Company")}